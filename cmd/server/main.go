@@ -1,14 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
 	. "github.com/naskavinda/webpageanalyzer/internal/handler"
+	"github.com/naskavinda/webpageanalyzer/internal/jobs"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish
+// once the server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	log.Println("[INFO] Starting Web Page Analyzer server...")
 	r := gin.Default()
@@ -21,12 +36,68 @@ func main() {
 		MaxAge:           12 * time.Hour,
 	}))
 
+	fetcher, err := newFetcher()
+	if err != nil {
+		log.Fatalf("[ERROR] Invalid network guard configuration: %v", err)
+	}
+	service := analyzer.DefaultAnalyzerService{Fetcher: fetcher}
+	if maxBodyBytes, err := strconv.ParseInt(os.Getenv("ANALYZER_MAX_BODY_BYTES"), 10, 64); err == nil && maxBodyBytes > 0 {
+		service.MaxBodyBytes = maxBodyBytes
+	}
+	if reject, err := strconv.ParseBool(os.Getenv("ANALYZER_REJECT_LARGE_BODIES")); err == nil {
+		service.RejectLargeBodies = reject
+	}
 	w := WebPageAnalyzer{
-		Service: analyzer.DefaultAnalyzerService{},
+		Service: service,
+	}
+	jobConfig := jobs.DefaultConfig
+	if workers, err := strconv.Atoi(os.Getenv("ANALYZER_JOB_WORKERS")); err == nil && workers > 0 {
+		jobConfig.Workers = workers
 	}
-	log.Println("[INFO] Registering /analyzer endpoint")
-	r.POST("/analyzer", w.WebPageAnalyzerHandler)
+	queue := jobs.NewQueue(service, jobConfig)
+	a := AnalysisJobs{Queue: queue}
+	log.Printf("[INFO] Registering /%s endpoints with %d job workers", APIVersion, jobConfig.Workers)
+	v1 := r.Group("/" + APIVersion)
+	RegisterRoutes(v1, w, a)
+	v1.GET("/schema", SchemaHandler)
+	log.Println("[INFO] Registering deprecated unversioned endpoints")
+	RegisterRoutes(r.Group("", Deprecated()), w, a)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Addr: ":8080", Handler: r}
+	go func() {
+		log.Println("[INFO] Server is running on :8080")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("[ERROR] Server failed: %v", err)
+		}
+	}()
 
-	log.Println("[INFO] Server is running on :8080")
-	r.Run()
+	<-ctx.Done()
+	stop()
+	log.Println("[INFO] Shutting down the server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("[ERROR] Server shutdown failed: %v", err)
+	}
+	queue.Close()
+	log.Println("[INFO] Server stopped")
+}
+
+// newFetcher builds the HTTP fetcher with the SSRF guard. Extra blocked
+// ranges and allow-list exceptions come from ANALYZER_BLOCKED_CIDRS and
+// ANALYZER_ALLOWED_CIDRS, both comma separated.
+func newFetcher() (analyzer.Fetcher, error) {
+	blocked, err := netguard.ParsePrefixes(strings.Split(os.Getenv("ANALYZER_BLOCKED_CIDRS"), ","))
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := netguard.ParsePrefixes(strings.Split(os.Getenv("ANALYZER_ALLOWED_CIDRS"), ","))
+	if err != nil {
+		return nil, err
+	}
+	config := analyzer.DefaultHTTPConfig
+	config.Guard = netguard.New(blocked, allowed)
+	return analyzer.NewHTTPFetcher(config), nil
 }
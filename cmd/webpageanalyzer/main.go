@@ -0,0 +1,313 @@
+// Command webpageanalyzer analyzes web pages without running the server.
+//
+//	webpageanalyzer [flags] URL...
+//	webpageanalyzer [flags] -f urls.txt
+//	webpageanalyzer [flags] -batch requests.jsonl
+//
+// It exits with status 1 when any page could not be analyzed, with status 2
+// on invalid usage and with status 3 when a page fails the -policy.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/batch"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/policy"
+	"github.com/naskavinda/webpageanalyzer/internal/render"
+)
+
+const (
+	exitOK      = 0
+	exitFailed  = 1
+	exitUsage   = 2
+	exitPolicy  = 3
+	formatTable = "table"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type config struct {
+	request      PageAnalysisRequest
+	urlFile      string
+	batchFile    string
+	format       string
+	concurrency  int
+	maxRedirects int
+	maxBodyBytes int64
+	rejectLarge  bool
+	verbose      bool
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	cfg, urls, err := parseFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "webpageanalyzer: %v\n", err)
+		return exitUsage
+	}
+
+	if !cfg.verbose {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	service := analyzer.DefaultAnalyzerService{
+		Fetcher:           analyzer.NewLocalFetcher(),
+		MaxRedirects:      cfg.maxRedirects,
+		MaxBodyBytes:      cfg.maxBodyBytes,
+		RejectLargeBodies: cfg.rejectLarge,
+	}
+
+	failed, policyFailed := false, false
+	var collected []batch.Result
+	emit := func(result batch.Result) {
+		if result.Error != "" {
+			failed = true
+		}
+		if result.Result != nil && result.Result.Policy != nil && !result.Result.Policy.Passed {
+			policyFailed = true
+		}
+		if _, ok := render.Encoders[cfg.format]; ok {
+			collected = append(collected, result)
+			return
+		}
+		if err := writeResult(stdout, cfg.format, result); err != nil {
+			fmt.Fprintf(stderr, "webpageanalyzer: %v\n", err)
+		}
+	}
+
+	if cfg.batchFile != "" {
+		input, closeInput, err := openInput(cfg.batchFile, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "webpageanalyzer: %v\n", err)
+			return exitUsage
+		}
+		defer closeInput()
+		err = batch.Run(ctx, service, input, cfg.concurrency, emit)
+		if err != nil {
+			fmt.Fprintf(stderr, "webpageanalyzer: %v\n", err)
+			return exitFailed
+		}
+	} else {
+		if cfg.urlFile != "" {
+			listed, err := readURLFile(cfg.urlFile, stdin)
+			if err != nil {
+				fmt.Fprintf(stderr, "webpageanalyzer: %v\n", err)
+				return exitUsage
+			}
+			urls = append(urls, listed...)
+		}
+		if len(urls) == 0 {
+			fmt.Fprintln(stderr, "webpageanalyzer: no URLs given")
+			return exitUsage
+		}
+		requests := make([]PageAnalysisRequest, 0, len(urls))
+		for _, url := range urls {
+			request := cfg.request
+			request.WebpageUrl = url
+			requests = append(requests, request)
+		}
+		if err := batch.RunRequests(ctx, service, requests, cfg.concurrency, emit); err != nil {
+			fmt.Fprintf(stderr, "webpageanalyzer: %v\n", err)
+			return exitFailed
+		}
+	}
+
+	if _, ok := render.Encoders[cfg.format]; ok {
+		// Whole-report formats list pages in input order.
+		sort.Slice(collected, func(i, j int) bool { return collected[i].Line < collected[j].Line })
+		analyses := make([]render.Analysis, 0, len(collected))
+		for _, result := range collected {
+			analyses = append(analyses, analysisOf(result))
+		}
+		if err := render.Write(stdout, cfg.format, analyses); err != nil {
+			fmt.Fprintf(stderr, "webpageanalyzer: %v\n", err)
+			return exitFailed
+		}
+	}
+
+	if failed {
+		return exitFailed
+	}
+	if policyFailed {
+		return exitPolicy
+	}
+	return exitOK
+}
+
+func parseFlags(args []string, stderr io.Writer) (config, []string, error) {
+	var cfg config
+	var checks, disabledChecks, restricted, policyFile string
+	var timeout, perHostInterval time.Duration
+	var headers headerFlag
+	linkCheck := LinkCheckLimits{}
+	checkInternalLinks := true
+
+	fs := flag.NewFlagSet("webpageanalyzer", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: webpageanalyzer [flags] URL...")
+		fmt.Fprintln(stderr, "       webpageanalyzer [flags] -f urls.txt")
+		fmt.Fprintln(stderr, "       webpageanalyzer [flags] -batch requests.jsonl")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&cfg.urlFile, "f", "", "read URLs from `file`, one per line (- for stdin)")
+	fs.StringVar(&cfg.batchFile, "batch", "", "read JSONL analysis requests from `file` (- for stdin); analysis flags are ignored")
+	fs.StringVar(&cfg.format, "format", formatTable, "output format: table, json, yaml, csv, markdown, html, sarif or junit")
+	fs.IntVar(&cfg.concurrency, "concurrency", batch.DefaultConcurrency, "pages analyzed at once")
+	fs.StringVar(&checks, "checks", "", "comma separated checks to run (default all)")
+	fs.StringVar(&disabledChecks, "disable-checks", "", "comma separated checks to skip")
+	fs.DurationVar(&timeout, "timeout", 0, "time limit per page, e.g. 30s (default none)")
+	fs.IntVar(&linkCheck.Workers, "link-workers", 0, "link checks in flight per page")
+	fs.IntVar(&linkCheck.PerHost, "link-per-host", 0, "link checks in flight per host")
+	fs.DurationVar(&perHostInterval, "link-per-host-interval", 0, "minimum spacing of link checks to one host")
+	fs.BoolVar(&cfg.request.IncludeLinks, "links", false, "report every link")
+	fs.BoolVar(&checkInternalLinks, "internal-links", true, "check internal links too")
+	fs.StringVar(&restricted, "restricted-status-codes", "", "comma separated link statuses reported as restricted (default 401,403)")
+	fs.StringVar(&cfg.request.UserAgent, "user-agent", "", "User-Agent sent with every request")
+	fs.Var(&headers, "header", "extra request `header` as 'Name: value' (repeatable)")
+	fs.StringVar(&policyFile, "policy", "", "evaluate the YAML or JSON policy in `file` against every page")
+	fs.IntVar(&cfg.maxRedirects, "max-redirects", analyzer.DefaultMaxRedirects, "redirects followed per request")
+	fs.Int64Var(&cfg.maxBodyBytes, "max-body-bytes", analyzer.DefaultMaxBodyBytes, "bytes of the page analyzed")
+	fs.BoolVar(&cfg.rejectLarge, "reject-large-bodies", false, "fail pages larger than -max-body-bytes instead of truncating them")
+	fs.BoolVar(&cfg.verbose, "v", false, "log analysis progress to stderr")
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+
+	if cfg.format != formatTable {
+		format, ok := render.FormatForName(cfg.format)
+		if !ok {
+			return cfg, nil, fmt.Errorf("unknown format: %s", cfg.format)
+		}
+		cfg.format = format
+	}
+	if cfg.batchFile != "" && (cfg.urlFile != "" || fs.NArg() > 0) {
+		return cfg, nil, fmt.Errorf("-batch cannot be combined with URLs or -f")
+	}
+
+	cfg.request.Checks = splitList(checks)
+	cfg.request.DisabledChecks = splitList(disabledChecks)
+	cfg.request.TimeoutMs = int(timeout / time.Millisecond)
+	linkCheck.PerHostIntervalMs = int(perHostInterval / time.Millisecond)
+	if linkCheck != (LinkCheckLimits{}) {
+		cfg.request.LinkCheck = &linkCheck
+	}
+	if !checkInternalLinks {
+		cfg.request.CheckInternalLinks = &checkInternalLinks
+	}
+	if restricted != "" {
+		codes := []int{}
+		for _, value := range splitList(restricted) {
+			code, err := strconv.Atoi(value)
+			if err != nil {
+				return cfg, nil, fmt.Errorf("invalid status code: %s", value)
+			}
+			codes = append(codes, code)
+		}
+		cfg.request.RestrictedStatusCodes = codes
+	}
+	if len(headers) > 0 {
+		cfg.request.Headers = headers
+	}
+	if policyFile != "" {
+		loaded, err := policy.Load(policyFile)
+		if err != nil {
+			return cfg, nil, err
+		}
+		cfg.request.Policy = &loaded
+	}
+	return cfg, fs.Args(), nil
+}
+
+// headerFlag collects repeated -header flags.
+type headerFlag map[string]string
+
+func (h *headerFlag) String() string {
+	return ""
+}
+
+func (h *headerFlag) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must look like 'Name: value'")
+	}
+	if *h == nil {
+		*h = make(headerFlag)
+	}
+	(*h)[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func openInput(name string, stdin io.Reader) (io.Reader, func(), error) {
+	if name == "-" {
+		return stdin, func() {}, nil
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, func() { file.Close() }, nil
+}
+
+// readURLFile reads one URL per line, skipping blank lines and # comments.
+func readURLFile(name string, stdin io.Reader) ([]string, error) {
+	input, closeInput, err := openInput(name, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer closeInput()
+
+	var urls []string
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+func writeResult(w io.Writer, format string, result batch.Result) error {
+	if format == render.FormatJSON {
+		return render.WriteJSON(w, []render.Analysis{analysisOf(result)})
+	}
+	return render.WriteTable(w, analysisOf(result))
+}
+
+func analysisOf(result batch.Result) render.Analysis {
+	return render.Analysis{URL: result.URL, Result: result.Result, Error: result.Error, Code: result.Code}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePage(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return "file://" + filepath.ToSlash(path)
+}
+
+// jsonResult is one line of -format json: the body of POST /analyzer.
+type jsonResult struct {
+	URL     string                `json:"url"`
+	Content *PageAnalysisResponse `json:"content"`
+	Error   string                `json:"error"`
+	Code    string                `json:"code"`
+}
+
+func TestRun_PrintsTable(t *testing.T) {
+	dir := t.TempDir()
+	writePage(t, dir, "about.html", `<html><title>About</title></html>`)
+	page := writePage(t, dir, "index.html", `<!DOCTYPE html><html><head><title>Home</title></head>
+		<body><h1>Welcome</h1><a href="about.html">About</a><a href="missing.html">Missing</a></body></html>`)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-links", page}, nil, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr.String())
+	output := stdout.String()
+	assert.Contains(t, output, "Title               Home\n")
+	assert.Contains(t, output, "Headings            h1: 1\n")
+	// Only http(s) links are checked, so links between local files are not.
+	assert.Contains(t, output, "Inaccessible links  0 (internal 0, external 0)\n")
+	assert.Contains(t, output, "Link unchecked      "+"file://"+filepath.ToSlash(filepath.Join(dir, "missing.html"))+" (line 2)\n")
+}
+
+func TestRun_PrintsJSONAndFailsOnFetchError(t *testing.T) {
+	dir := t.TempDir()
+	page := writePage(t, dir, "index.html", `<html><title>Home</title></html>`)
+	urls := filepath.Join(dir, "urls.txt")
+	os.WriteFile(urls, []byte("# pages\n"+page+"\n\n"+"file://"+filepath.ToSlash(filepath.Join(dir, "gone.html"))+"\n"), 0o644)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-format", "json", "-concurrency", "1", "-f", urls}, nil, &stdout, &stderr)
+
+	assert.Equal(t, exitFailed, code)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Len(t, lines, 2)
+	results := make(map[string]jsonResult)
+	for _, line := range lines {
+		var result jsonResult
+		assert.NoError(t, json.Unmarshal([]byte(line), &result))
+		results[result.URL] = result
+	}
+	assert.Equal(t, "Home", results[page].Content.Title)
+	gone := results["file://"+filepath.ToSlash(filepath.Join(dir, "gone.html"))]
+	assert.Nil(t, gone.Content)
+	assert.Contains(t, gone.Error, "404")
+}
+
+func TestRun_RejectsLargeBodies(t *testing.T) {
+	dir := t.TempDir()
+	page := writePage(t, dir, "index.html", `<html><title>A page larger than the limit</title></html>`)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-format", "json", "-max-body-bytes", "16", "-reject-large-bodies", page}, nil, &stdout, &stderr)
+
+	assert.Equal(t, exitFailed, code)
+	var result jsonResult
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.Equal(t, analyzer.CodeBodyTooLarge, result.Code)
+}
+
+func TestRun_ReadsBatchFromStdin(t *testing.T) {
+	dir := t.TempDir()
+	page := writePage(t, dir, "index.html", `<html><title>Home</title><h2>Sub</h2></html>`)
+	input := `{"webpageUrl":"` + page + `","checks":["title"]}` + "\n"
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-format", "json", "-batch", "-"}, strings.NewReader(input), &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	var result jsonResult
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.Equal(t, page, result.URL)
+	assert.Equal(t, "Home", result.Content.Title)
+	assert.Empty(t, result.Content.HeadingCounts)
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no URLs", nil, "no URLs given"},
+		{"unknown format", []string{"-format", "pdf", "https://example.com"}, "unknown format: pdf"},
+		{"bad header", []string{"-header", "nocolon", "https://example.com"}, "header must look like 'Name: value'"},
+		{"bad status code", []string{"-restricted-status-codes", "40x", "https://example.com"}, "invalid status code: 40x"},
+		{"batch with URLs", []string{"-batch", "-", "https://example.com"}, "-batch cannot be combined with URLs or -f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.args, nil, &stdout, &stderr)
+			assert.Equal(t, exitUsage, code)
+			assert.Contains(t, stderr.String(), tt.want)
+		})
+	}
+}
+
+func TestParseFlags_MapsAnalyzerOptions(t *testing.T) {
+	cfg, urls, err := parseFlags([]string{
+		"-checks", "title, links", "-disable-checks", "loginForm", "-timeout", "1.5s",
+		"-link-workers", "2", "-link-per-host-interval", "250ms", "-internal-links=false",
+		"-restricted-status-codes", "401", "-user-agent", "ci-bot", "-header", "X-Token: abc",
+		"https://example.com",
+	}, &bytes.Buffer{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com"}, urls)
+	assert.Equal(t, []string{"title", "links"}, cfg.request.Checks)
+	assert.Equal(t, []string{"loginForm"}, cfg.request.DisabledChecks)
+	assert.Equal(t, 1500, cfg.request.TimeoutMs)
+	assert.Equal(t, 2, cfg.request.LinkCheck.Workers)
+	assert.Equal(t, 250, cfg.request.LinkCheck.PerHostIntervalMs)
+	assert.False(t, *cfg.request.CheckInternalLinks)
+	assert.Equal(t, []int{401}, cfg.request.RestrictedStatusCodes)
+	assert.Equal(t, "ci-bot", cfg.request.UserAgent)
+	assert.Equal(t, map[string]string{"X-Token": "abc"}, cfg.request.Headers)
+}
+
+func TestRun_FailsWhenPolicyFails(t *testing.T) {
+	dir := t.TempDir()
+	page := writePage(t, dir, "index.html", `<html><title>Home</title><h2>Sub</h2></html>`)
+	policyFile := filepath.Join(dir, "policy.yaml")
+	os.WriteFile(policyFile, []byte("rules:\n  - name: has h1\n    field: HeadingCounts.h1\n    op: \">=\"\n    value: 1\n  - field: Title\n    op: notEmpty\n"), 0o644)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-policy", policyFile, page}, nil, &stdout, &stderr)
+
+	assert.Equal(t, exitPolicy, code)
+	assert.Contains(t, stdout.String(), "Policy              FAIL\n")
+	assert.Contains(t, stdout.String(), "  FAIL              has h1: HeadingCounts.h1 is missing\n")
+	assert.Contains(t, stdout.String(), `  PASS              Title notEmpty: Title is "Home"`)
+}
+
+func TestRun_WritesJUnitInInputOrder(t *testing.T) {
+	dir := t.TempDir()
+	first := writePage(t, dir, "first.html", `<html><title>First</title><h1>One</h1></html>`)
+	second := writePage(t, dir, "second.html", `<html><h1>Two</h1></html>`)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-format", "junit", second, first}, nil, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	output := stdout.String()
+	assert.Less(t, strings.Index(output, `<testsuite name="`+second), strings.Index(output, `<testsuite name="`+first))
+	assert.Contains(t, output, `<failure message="1 finding(s): The page has no title." type="missing-title">`)
+}
@@ -4,17 +4,18 @@ import './App.css'
 function App() {
   const [url, setUrl] = useState('')
   const [result, setResult] = useState<null | {
-    URL: string;
-    HTMLVersion: string;
-    Title: string;
-    HeadingCounts: { h1: number; h2: number; h3: number };
-    InternalLinks: number;
-    ExternalLinks: number;
-    InaccessibleLinks: number;
-    HasLoginForm: boolean;
+    url: string;
+    htmlVersion: string;
+    title: string;
+    headingCounts: { h1: number; h2: number; h3: number };
+    internalLinks: number;
+    externalLinks: number;
+    inaccessibleLinks: number;
+    hasLoginForm: boolean;
   }>(null)
   const [loading, setLoading] = useState(false)
   const [error, setError] = useState<string | null>(null)
+  const [progress, setProgress] = useState<string | null>(null)
 
   const isValidUrl = (input: string) => {
     try {
@@ -25,30 +26,39 @@ function App() {
     }
   }
 
-  const handleSubmit = async (e: React.FormEvent) => {
+  const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault()
     setLoading(true)
     setError(null)
     setResult(null)
-    try {
-      const response = await fetch('http://localhost:8080/analyzer', {
-        method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ webpageUrl: url }),
-      })
-      
-      const data = await response.json()
-      if (!response.ok) throw new Error(data.error || 'Failed to analyze webpage')
-      setResult(data.content)
-    } catch (err) {
-      if (err instanceof Error) {
-        setError(err.message)
-      } else {
-        setError('An unknown error occurred')
-      }
-    } finally {
+    setProgress(null)
+
+    const source = new EventSource(
+      `http://localhost:8080/v1/analyzer/stream?webpageUrl=${encodeURIComponent(url)}`
+    )
+    const finish = () => {
+      source.close()
       setLoading(false)
     }
+    source.addEventListener('fetched', () => setProgress('Fetched page'))
+    source.addEventListener('parsed', () => setProgress('Parsed page'))
+    source.addEventListener('check', (event) => {
+      const data = JSON.parse((event as MessageEvent).data)
+      setProgress(`Finished ${data.check} check`)
+    })
+    source.addEventListener('link', (event) => {
+      const data = JSON.parse((event as MessageEvent).data)
+      setProgress(`Checked ${data.checked} of ${data.total} links`)
+    })
+    source.addEventListener('done', (event) => {
+      setResult(JSON.parse((event as MessageEvent).data).result)
+      finish()
+    })
+    source.addEventListener('error', (event) => {
+      const data = (event as MessageEvent).data
+      setError(data ? JSON.parse(data).detail : 'Failed to analyze webpage')
+      finish()
+    })
   }
 
   return (
@@ -66,18 +76,19 @@ function App() {
           {loading ? 'Analyzing...' : 'Analyze'}
         </button>
       </form>
+      {loading && progress && <p>{progress}</p>}
       {error && <p style={{ color: 'red' }}>{error}</p>}
       {result && (
         <div style={{ marginTop: 24, textAlign: 'left', background: '#f8f8f8', padding: 16, borderRadius: 8 }}>
           <h2>Analysis Result</h2>
-          <p><strong>URL:</strong> {result.URL}</p>
-          <p><strong>HTML Version:</strong> {result.HTMLVersion}</p>
-          <p><strong>Title:</strong> {result.Title}</p>
-          <p><strong>Headings:</strong> h1: {result.HeadingCounts.h1}, h2: {result.HeadingCounts.h2}, h3: {result.HeadingCounts.h3}</p>
-          <p><strong>Internal Links:</strong> {result.InternalLinks}</p>
-          <p><strong>External Links:</strong> {result.ExternalLinks}</p>
-          <p><strong>Inaccessible Links:</strong> {result.InaccessibleLinks}</p>
-          <p><strong>Has Login Form:</strong> {result.HasLoginForm ? 'Yes' : 'No'}</p>
+          <p><strong>URL:</strong> {result.url}</p>
+          <p><strong>HTML Version:</strong> {result.htmlVersion}</p>
+          <p><strong>Title:</strong> {result.title}</p>
+          <p><strong>Headings:</strong> h1: {result.headingCounts.h1}, h2: {result.headingCounts.h2}, h3: {result.headingCounts.h3}</p>
+          <p><strong>Internal Links:</strong> {result.internalLinks}</p>
+          <p><strong>External Links:</strong> {result.externalLinks}</p>
+          <p><strong>Inaccessible Links:</strong> {result.inaccessibleLinks}</p>
+          <p><strong>Has Login Form:</strong> {result.hasLoginForm ? 'Yes' : 'No'}</p>
         </div>
       )}
     </div>
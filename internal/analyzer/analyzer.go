@@ -1,220 +1,342 @@
 package analyzer
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
 	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
+	"github.com/naskavinda/webpageanalyzer/internal/policy"
 	"github.com/naskavinda/webpageanalyzer/internal/validator"
+	"io"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
-	"sync"
 )
 
-var HTTPGet = http.Get
-var HTTPClient = http.Client{}
+type DefaultAnalyzerService struct {
+	Registry               *Registry
+	Fetcher                Fetcher
+	LinkCheckLimits        LinkCheckLimits
+	SkipInternalLinkChecks bool
+	LinkCheckRules         *LinkCheckRules
+	MaxRedirects           int
+	// MaxBodyBytes caps how much of the page is read; anything beyond is
+	// dropped and reported as a warning, or fails the analysis with
+	// CodeBodyTooLarge when RejectLargeBodies is set.
+	MaxBodyBytes      int64
+	RejectLargeBodies bool
+	UserAgent         string
+	Headers           map[string]string
+}
+
+const DefaultMaxBodyBytes = 5 << 20
 
-type DefaultAnalyzerService struct{}
+const DefaultUserAgent = "WebPageAnalyzer/1.0 (+https://github.com/naskavinda/webpageanalyzer)"
 
-func (defaultAnalyzer DefaultAnalyzerService) Analyze(pageUrl string) (PageAnalysisResponse, error) {
+func (defaultAnalyzer DefaultAnalyzerService) Analyze(ctx context.Context, pageUrl string, options ...Option) (PageAnalysisResponse, error) {
 	log.Printf("[DEBUG] Starting analysis for URL: %s", pageUrl)
+	opts := newOptions(options)
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
 	var isValidURL = false
 
-	isValidURL = validator.IsValidURL(&pageUrl)
+	fetcher := defaultAnalyzer.fetcher()
+	isValidURL = validator.IsValidURLWithSchemes(&pageUrl, supportedSchemes(fetcher)...)
 
 	if !isValidURL {
 		log.Printf("[ERROR] Invalid URL format: %s", pageUrl)
-		return PageAnalysisResponse{}, fmt.Errorf("invalid URL format")
+		return PageAnalysisResponse{}, newError(CodeInvalidInput, nil, "invalid URL format")
+	}
+
+	checks, err := defaultAnalyzer.registry().Select(opts.EnabledChecks, opts.DisabledChecks)
+	if err != nil {
+		log.Printf("[ERROR] Invalid check selection for %s: %v", pageUrl, err)
+		return PageAnalysisResponse{}, newError(CodeInvalidInput, err, "%v", err)
 	}
 
-	resp, err := HTTPGet(pageUrl)
+	if opts.Policy != nil {
+		if err := policy.Validate(*opts.Policy); err != nil {
+			log.Printf("[ERROR] Invalid policy for %s: %v", pageUrl, err)
+			return PageAnalysisResponse{}, newError(CodeInvalidInput, err, "%v", err)
+		}
+	}
+
+	opts.maxRedirects = defaultAnalyzer.MaxRedirects
+	opts.Progress = serializeProgress(opts.Progress)
+	defaultAnalyzer.applyRequestDefaults(&opts)
+	resp, redirects, err := fetchPage(ctx, fetcher, pageUrl, opts)
 	if err != nil {
 		log.Printf("[ERROR] Failed to fetch the webpage: %v", err)
-		return PageAnalysisResponse{}, fmt.Errorf("failed to fetch the webpage")
+		failure := fetchError(ctx, err, redirects)
+		if failure.Redirects == nil {
+			return PageAnalysisResponse{}, failure
+		}
+		return PageAnalysisResponse{URL: pageUrl, FinalURL: failure.Redirects.FinalURL, Redirects: failure.Redirects}, failure
 	}
 	defer resp.Body.Close()
+	opts.emit(ProgressEvent{Type: EventFetched, URL: redirects.FinalURL, StatusCode: resp.StatusCode})
 
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("[ERROR] Non-200 status code for %s: %v", pageUrl, resp.Status)
-		return PageAnalysisResponse{}, fmt.Errorf("failed to fetch the webpage, status code: %v", resp.Status)
+		return PageAnalysisResponse{}, &Error{
+			Code:           CodeUpstreamStatus,
+			Message:        fmt.Sprintf("failed to fetch the webpage, status code: %v", resp.Status),
+			UpstreamStatus: resp.StatusCode,
+		}
+	}
+	if !isHTML(resp.Header.Get("Content-Type")) {
+		log.Printf("[ERROR] Webpage %s is not HTML: %s", pageUrl, resp.Header.Get("Content-Type"))
+		return PageAnalysisResponse{}, newError(CodeNonHTMLContent, nil, "the webpage is not HTML: %s", resp.Header.Get("Content-Type"))
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	maxBodyBytes := defaultAnalyzer.maxBodyBytes()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes+1))
 	if err != nil {
 		log.Printf("[ERROR] Failed to read the webpage content for %s: %v", pageUrl, err)
-		return PageAnalysisResponse{}, fmt.Errorf("failed to read the webpage content")
+		if ctx.Err() != nil {
+			return PageAnalysisResponse{}, newError(contextErrorCode(ctx), ctx.Err(), "failed to read the webpage content: %v", ctx.Err())
+		}
+		return PageAnalysisResponse{}, newError(CodeFetchFailed, err, "failed to read the webpage content")
 	}
-
-	result := PageAnalysisResponse{
-		URL:           pageUrl,
-		HeadingCounts: make(map[string]int),
+	var warnings []Issue
+	if int64(len(body)) > maxBodyBytes && defaultAnalyzer.RejectLargeBodies {
+		log.Printf("[ERROR] Webpage %s is larger than %d bytes", pageUrl, maxBodyBytes)
+		return PageAnalysisResponse{}, newError(CodeBodyTooLarge, nil, "the webpage is larger than %d bytes", maxBodyBytes)
+	}
+	if int64(len(body)) > maxBodyBytes {
+		log.Printf("[INFO] Webpage %s is larger than %d bytes, analyzing a truncated document", pageUrl, maxBodyBytes)
+		body = body[:maxBodyBytes]
+		warnings = append(warnings, Issue{
+			Code:    IssueBodyTruncated,
+			Message: fmt.Sprintf("page is larger than %d bytes; only the first %d bytes were analyzed", maxBodyBytes, maxBodyBytes),
+		})
 	}
 
-	result.HTMLVersion = detectHTMLVersion(doc)
-	log.Printf("[DEBUG] Detected HTML version for %s: %s", pageUrl, result.HTMLVersion)
-
-	result.Title = doc.Find("title").Text()
-	log.Printf("[DEBUG] Page title for %s: %s", pageUrl, result.Title)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[ERROR] Failed to read the webpage content for %s: %v", pageUrl, err)
+		return PageAnalysisResponse{}, newError(CodeNonHTMLContent, err, "failed to read the webpage content")
+	}
 
-	getHeadingCount(doc, result)
+	opts.emit(ProgressEvent{Type: EventParsed, URL: redirects.FinalURL, Bytes: len(body)})
 
-	parsedURL, err := getUrl(pageUrl, err)
+	if redirects.HopCount > 0 {
+		log.Printf("[DEBUG] %s redirected %d time(s) to %s", pageUrl, redirects.HopCount, redirects.FinalURL)
+	}
+	parsedURL, err := getUrl(redirects.FinalURL, err)
 	if err != nil {
 		log.Printf("[ERROR] Failed to parse URL %s: %v", pageUrl, err)
-		return PageAnalysisResponse{}, err
+		return PageAnalysisResponse{}, newError(CodeInvalidInput, err, "%v", err)
 	}
 
-	internalCount, externalCount, inaccessibleCount := linksAnalyzer(doc, parsedURL)
+	result := PageAnalysisResponse{
+		URL:           pageUrl,
+		FinalURL:      redirects.FinalURL,
+		Redirects:     chainOrNil(redirects),
+		HeadingCounts: make(map[string]int),
+		Warnings:      warnings,
+	}
+	opts.LinkCheckLimits = resolveLinkCheckLimits(defaultAnalyzer.LinkCheckLimits, opts.LinkCheckLimits)
+	opts.linkRules = defaultAnalyzer.LinkCheckRules
+	if opts.CheckInternalLinks == nil {
+		checkInternal := !defaultAnalyzer.SkipInternalLinkChecks
+		opts.CheckInternalLinks = &checkInternal
+	}
+	page := &Page{URL: parsedURL, Header: resp.Header, Body: body, Document: doc, Fetcher: fetcher, Options: opts}
 
-	result.InternalLinks = internalCount
-	result.ExternalLinks = externalCount
-	result.InaccessibleLinks = inaccessibleCount
+	for i, check := range checks {
+		if ctx.Err() != nil {
+			log.Printf("[ERROR] Analysis of %s stopped before check %s: %v", pageUrl, check.Name(), ctx.Err())
+			result.TimedOut = true
+			skipChecks(&result, checks[i:])
+			break
+		}
+		warnings := len(result.Warnings)
+		section, err := runCheck(ctx, check, page, &result)
+		for j := warnings; j < len(result.Warnings); j++ {
+			result.Warnings[j].Check = check.Name()
+		}
+		if section != nil {
+			if result.Sections == nil {
+				result.Sections = make(map[string]any)
+			}
+			result.Sections[check.Name()] = section
+		}
+		if isContextError(err) {
+			log.Printf("[ERROR] Check %s for %s did not finish: %v", check.Name(), pageUrl, err)
+			result.TimedOut = true
+			result.Errors = append(result.Errors, Issue{
+				Code:    IssueCheckTimedOut,
+				Check:   check.Name(),
+				Message: "the check did not finish before the analysis timed out",
+			})
+			skipChecks(&result, checks[i+1:])
+			break
+		}
+		if err != nil {
+			log.Printf("[ERROR] Check %s failed for %s: %v", check.Name(), pageUrl, err)
+			result.Errors = append(result.Errors, Issue{Code: IssueCheckFailed, Check: check.Name(), Message: err.Error()})
+		} else {
+			result.Checks = append(result.Checks, check.Name())
+		}
+		opts.emit(ProgressEvent{Type: EventCheck, Check: check.Name(), Result: &result})
+	}
 
-	result.HasLoginForm = detectLoginForm(doc)
+	if opts.Policy != nil {
+		report := policy.Evaluate(*opts.Policy, result)
+		result.Policy = &report
+		log.Printf("[DEBUG] Policy for %s passed: %v", pageUrl, report.Passed)
+	}
 
 	log.Printf("[INFO] Analysis complete for %s", pageUrl)
+	opts.emit(ProgressEvent{Type: EventDone, Result: &result})
 	return result, nil
 }
 
-func getUrl(pageUrl string, err error) (*url.URL, error) {
-	parsedURL, err := url.Parse(pageUrl)
-	if err != nil {
-		log.Printf("[ERROR] url.Parse failed for %s: %v", pageUrl, err)
-		return nil, fmt.Errorf("given URL is invalid")
+func (defaultAnalyzer DefaultAnalyzerService) registry() *Registry {
+	if defaultAnalyzer.Registry != nil {
+		return defaultAnalyzer.Registry
 	}
-	return parsedURL, nil
+	return defaultRegistry
 }
 
-func linksAnalyzer(doc *goquery.Document, baseUrl *url.URL) (int, int, int) {
-
-	var internalCount, externalCount, inaccessibleCount int
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	links := doc.Find("a[href]")
-
-	links.Each(func(i int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
+func (defaultAnalyzer DefaultAnalyzerService) fetcher() Fetcher {
+	if defaultAnalyzer.Fetcher != nil {
+		return defaultAnalyzer.Fetcher
+	}
+	return defaultFetcher
+}
 
-		if !exists || href == "" || href == "#" {
-			return
-		}
+// defaultFetcher is used when no Fetcher is configured. It refuses internal
+// addresses, since the service usually runs next to things that should not
+// be reachable through it.
+var defaultFetcher = NewHTTPFetcher(HTTPConfig{
+	ConnectTimeout:        DefaultHTTPConfig.ConnectTimeout,
+	TLSHandshakeTimeout:   DefaultHTTPConfig.TLSHandshakeTimeout,
+	ResponseHeaderTimeout: DefaultHTTPConfig.ResponseHeaderTimeout,
+	Timeout:               DefaultHTTPConfig.Timeout,
+	Guard:                 netguard.New(nil, nil),
+})
+
+func (defaultAnalyzer DefaultAnalyzerService) maxBodyBytes() int64 {
+	if defaultAnalyzer.MaxBodyBytes > 0 {
+		return defaultAnalyzer.MaxBodyBytes
+	}
+	return DefaultMaxBodyBytes
+}
 
-		linkUrl, err := url.Parse(href)
+// applyRequestDefaults fills the request headers of opts from the service:
+// the request User-Agent wins, and request headers override service
+// headers of the same name.
+func (defaultAnalyzer DefaultAnalyzerService) applyRequestDefaults(opts *Options) {
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaultAnalyzer.UserAgent
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+	headers := make(map[string]string, len(defaultAnalyzer.Headers)+len(opts.Headers))
+	for name, value := range defaultAnalyzer.Headers {
+		headers[name] = value
+	}
+	for name, value := range opts.Headers {
+		headers[name] = value
+	}
+	opts.Headers = headers
+}
 
-		if err != nil {
-			log.Printf("[ERROR] Failed to parse link href: %s, error: %v", href, err)
-			mu.Lock()
-			inaccessibleCount++
-			mu.Unlock()
-			return
-		}
+var defaultRegistry = DefaultRegistry()
 
-		if !linkUrl.IsAbs() {
-			linkUrl = baseUrl.ResolveReference(linkUrl)
-		}
+func fetchPage(ctx context.Context, fetcher Fetcher, pageUrl string, opts Options) (*http.Response, *RedirectChain, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.setRequestHeaders(req)
+	return followRedirects(fetcher, req, opts.maxRedirects)
+}
 
-		if linkUrl.Host == baseUrl.Host {
-			mu.Lock()
-			internalCount++
-			mu.Unlock()
-		} else {
-			mu.Lock()
-			externalCount++
-			mu.Unlock()
-			wg.Add(1)
-			go func(link string) {
-
-				defer wg.Done()
-
-				if !isLinkAccessible(link) {
-					log.Printf("[DEBUG] Link inaccessible: %s", link)
-					mu.Lock()
-					inaccessibleCount++
-					mu.Unlock()
-				}
-
-			}(linkUrl.String())
+// runCheck runs check, turning a panic into an error so that one broken
+// check cannot take the results of the others with it.
+func runCheck(ctx context.Context, check Check, page *Page, result *PageAnalysisResponse) (section any, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("check panicked: %v", recovered)
 		}
-
-	})
-	wg.Wait()
-	return internalCount, externalCount, inaccessibleCount
+	}()
+	return check.Run(ctx, page, result)
 }
 
-func isLinkAccessible(link string) bool {
-	resp, err := HTTPClient.Head(link)
-	if err != nil || resp.StatusCode >= 400 {
-		log.Printf("[DEBUG] Link not accessible: %s, err: %v, status: %v", link, err, resp)
-		return false
-	}
-	if resp != nil {
-		resp.Body.Close()
+// skipChecks records the checks that were not run because the analysis ran
+// out of time.
+func skipChecks(result *PageAnalysisResponse, checks []Check) {
+	for _, check := range checks {
+		result.Errors = append(result.Errors, Issue{
+			Code:    IssueCheckSkipped,
+			Check:   check.Name(),
+			Message: "the check was skipped because the analysis timed out",
+		})
 	}
-	return true
 }
 
-func getHeadingCount(doc *goquery.Document, result PageAnalysisResponse) {
-	for i := 0; i < 7; i++ {
-		selector := fmt.Sprintf("h%d", i)
-		count := doc.Find(selector).Length()
-		if count > 0 {
-			result.HeadingCounts[selector] = count
-		}
-	}
+func isContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
 }
 
-func detectHTMLVersion(doc *goquery.Document) string {
-	html, err := doc.Html()
-	if err != nil {
-		return "Unknown"
-	}
+func htmlVersionCheck(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	doctype := parseDoctype(page.Body)
+	result.HTMLVersion = htmlVersionLabel(doctype)
+	result.Doctype = &doctype
+	log.Printf("[DEBUG] Detected HTML version for %s: %s", page.URL, result.HTMLVersion)
+	return nil, nil
+}
 
-	lowerCaseHTML := strings.ToLower(html)
-	if strings.Contains(lowerCaseHTML, "<!doctype html>") {
-		return "HTML5"
-	}
+func titleCheck(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	result.Title = documentTitle(page.Document)
+	log.Printf("[DEBUG] Page title for %s: %s", page.URL, result.Title)
+	return nil, nil
+}
 
-	if strings.Contains(lowerCaseHTML, "html 4.01") {
-		return "HTML 4.01"
+func getUrl(pageUrl string, err error) (*url.URL, error) {
+	parsedURL, err := url.Parse(pageUrl)
+	if err != nil {
+		log.Printf("[ERROR] url.Parse failed for %s: %v", pageUrl, err)
+		return nil, fmt.Errorf("given URL is invalid")
 	}
+	return parsedURL, nil
+}
 
-	if strings.Contains(lowerCaseHTML, "xhtml 1.0") {
-		return "XHTML 1.0"
+// resolveURL resolves ref, an href or form action, against base. It returns
+// ref as written when there is no base or ref does not parse.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if base == nil {
+		return ref
 	}
-	if strings.Contains(lowerCaseHTML, "xhtml 1.1") {
-		return "XHTML 1.1"
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return ref
 	}
-
-	return "Unknown"
+	return base.ResolveReference(parsed).String()
 }
 
-func detectLoginForm(doc *goquery.Document) bool {
-
-	loginTexts := []string{"login", "log in", "sign in", "sign up"}
-	hasLoginText := false
-
-	formHTML, _ := doc.Html()
-	formText := strings.ToLower(formHTML)
-	hasPasswordField := strings.Contains(formText, "type='password'") || strings.Contains(formText, "type=\"password\"")
-
-	for _, text := range loginTexts {
-		if strings.Contains(formText, text) {
-			hasLoginText = true
-			break
-		}
+// isHTML reports whether a Content-Type names an HTML document. Responses
+// without one are analyzed anyway.
+func isHTML(contentType string) bool {
+	if contentType == "" {
+		return true
 	}
-
-	action, _ := doc.Attr("action")
-	id, _ := doc.Attr("id")
-
-	for _, text := range loginTexts {
-		if strings.Contains(strings.ToLower(action), text) || strings.Contains(strings.ToLower(id), text) {
-			hasLoginText = true
-			break
-		}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
 	}
-
-	return hasPasswordField || hasLoginText
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
 }
@@ -1,9 +1,11 @@
 package analyzer
 
 import (
+	"context"
+
 	"github.com/naskavinda/webpageanalyzer/internal/model"
 )
 
 type Service interface {
-	Analyze(url string) (model.PageAnalysisResponse, error)
+	Analyze(ctx context.Context, url string, options ...Option) (model.PageAnalysisResponse, error)
 }
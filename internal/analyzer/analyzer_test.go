@@ -1,25 +1,24 @@
 package analyzer
 
 import (
+	"context"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
 	"github.com/stretchr/testify/assert"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
-var originalHTTPGet = HTTPGet
-var originalHTTPClient = HTTPClient
-
 func TestAnalyze_ValidPageURL(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	pageUrl := "https://google.com"
-	d := DefaultAnalyzerService{}
-	analyze, err := d.Analyze(pageUrl)
+	d := newFixtureService(pageUrl, Fixture{Body: validHTMLContentWithoutHeaders})
+	analyze, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.NoError(t, err)
 	assert.Equal(t, pageUrl, analyze.URL)
@@ -29,7 +28,7 @@ func TestAnalyze_InvalidPageURL(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	pageUrl := "invalid-url"
 	d := DefaultAnalyzerService{}
-	_, err := d.Analyze(pageUrl)
+	_, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.Error(t, err)
 	assert.Equal(t, "invalid URL format", err.Error())
@@ -39,8 +38,8 @@ func TestAnalyze_PageNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	pageUrl := "https://example.com/404"
-	d := DefaultAnalyzerService{}
-	_, err := d.Analyze(pageUrl)
+	d := DefaultAnalyzerService{Fetcher: NewFixtureFetcher(nil)}
+	_, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to fetch the webpage, status code: 404")
@@ -51,7 +50,7 @@ func TestAnalyze_EmptyPageURL(t *testing.T) {
 
 	pageUrl := ""
 	d := DefaultAnalyzerService{}
-	_, err := d.Analyze(pageUrl)
+	_, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.Error(t, err)
 	assert.Equal(t, "invalid URL format", err.Error())
@@ -62,12 +61,8 @@ func TestAnalyze_ShouldGiveValidHTMLVersion(t *testing.T) {
 
 	pageUrl := "https://example.com/test-page"
 
-	response := mockHTTPGetSuccess()
-	cleanUp := setupMockHTTP(response, nil)
-	d := DefaultAnalyzerService{}
-	analyze, err := d.Analyze(pageUrl)
-
-	defer cleanUp()
+	d := newFixtureService(pageUrl, Fixture{Body: "<!DOCTYPE html><body>Test Page</body></html>"})
+	analyze, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.NoError(t, err)
 	assert.Equal(t, pageUrl, analyze.URL)
@@ -80,11 +75,8 @@ func TestAnalyze_ShouldReturnErrorOnHTTPFailure(t *testing.T) {
 	pageUrl := "https://example.com/test-page"
 
 	mockError := http.ErrHandlerTimeout
-	cleanUp := setupMockHTTP(nil, mockError)
-	defer cleanUp()
-
-	d := DefaultAnalyzerService{}
-	_, err := d.Analyze(pageUrl)
+	d := newFixtureService(pageUrl, Fixture{Err: mockError})
+	_, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.Error(t, err)
 	assert.Equal(t, "failed to fetch the webpage", err.Error())
@@ -95,12 +87,8 @@ func TestAnalyze_ShouldReturnSuccessResponse_WithHeading(t *testing.T) {
 
 	pageUrl := "https://example.com/test-page"
 
-	response := newHTTPResponse(validHTMLContentWithHeaders, http.StatusOK)
-	cleanUp := setupMockHTTP(response, nil)
-	defer cleanUp()
-
-	d := DefaultAnalyzerService{}
-	analyze, err := d.Analyze(pageUrl)
+	d := newFixtureService(pageUrl, Fixture{Body: validHTMLContentWithHeaders})
+	analyze, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.NoError(t, err)
 	assert.Equal(t, pageUrl, analyze.URL)
@@ -115,12 +103,8 @@ func TestAnalyze_ShouldReturnSuccessResponse_WithoutHeading(t *testing.T) {
 
 	pageUrl := "https://example.com/test-page"
 
-	response := newHTTPResponse(validHTMLContentWithoutHeaders, http.StatusOK)
-	cleanUp := setupMockHTTP(response, nil)
-	defer cleanUp()
-
-	d := DefaultAnalyzerService{}
-	analyze, err := d.Analyze(pageUrl)
+	d := newFixtureService(pageUrl, Fixture{Body: validHTMLContentWithoutHeaders})
+	analyze, err := d.Analyze(context.Background(), pageUrl)
 
 	assert.NoError(t, err)
 	assert.Equal(t, pageUrl, analyze.URL)
@@ -165,6 +149,31 @@ func TestDetectHTMLVersion(t *testing.T) {
 			html:     "<html><body>No doctype</body></html>",
 			expected: "Unknown",
 		},
+		{
+			name:     "Doctype only mentioned in content",
+			html:     "<html><body><code>&lt;!DOCTYPE html&gt;</code> <!-- <!doctype html> --></body></html>",
+			expected: "Unknown",
+		},
+		{
+			name:     "HTML5 after XML declaration and comment",
+			html:     "\ufeff<?xml version=\"1.0\"?>\n<!-- generated -->\n<!doctype HTML SYSTEM \"about:legacy-compat\"><html></html>",
+			expected: "HTML5",
+		},
+		{
+			name:     "HTML 3.2",
+			html:     "<!DOCTYPE HTML PUBLIC \"-//W3C//DTD HTML 3.2 Final//EN\"><html></html>",
+			expected: "HTML 3.2",
+		},
+		{
+			name:     "XHTML Basic",
+			html:     "<!DOCTYPE html PUBLIC \"-//W3C//DTD XHTML Basic 1.1//EN\" \"http://www.w3.org/TR/xhtml-basic/xhtml-basic11.dtd\"><html></html>",
+			expected: "XHTML Basic 1.1",
+		},
+		{
+			name:     "XHTML Mobile",
+			html:     "<!DOCTYPE html PUBLIC \"-//WAPFORUM//DTD XHTML Mobile 1.2//EN\"><html></html>",
+			expected: "XHTML Mobile 1.2",
+		},
 	}
 
 	for _, tt := range tests {
@@ -176,10 +185,7 @@ func TestDetectHTMLVersion(t *testing.T) {
 }
 
 func isHtmlVersionCorrect(t *testing.T, htmlContent string, expectedVersion string) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
-	assert.NoError(t, err)
-
-	version := detectHTMLVersion(doc)
+	version := detectHTMLVersion([]byte(htmlContent))
 	assert.Equal(t, expectedVersion, version)
 }
 
@@ -192,17 +198,21 @@ func TestLinksAnalyzer_ShouldReturnInternalAndExternalLinkCount(t *testing.T) {
 	parsedURL, err := url.Parse(pageUrl)
 	assert.NoError(t, err)
 
-	response := newHTTPResponse(validHTMLContentWithHeaders, http.StatusOK)
-	cleanUp := setupMockHTTP(response, nil)
-	defer cleanUp()
+	fetcher := NewFixtureFetcher(map[string]Fixture{
+		"https://www.example.com": {},
+		"https://www.openai.com":  {},
+	})
 
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(validHTMLContentWithHeaders))
 	assert.NoError(t, err)
 
-	internalCount, externalCount, _ := linksAnalyzer(doc, parsedURL)
+	page := &Page{URL: parsedURL, Body: []byte(validHTMLContentWithHeaders), Document: doc, Fetcher: fetcher,
+		Options: Options{LinkCheckLimits: DefaultLinkCheckLimits}}
+	internalCount, externalCount, inaccessibleCount := summarizeLinks(linksAnalyzer(context.Background(), page, nil))
 
 	assert.Equal(t, 5, internalCount) // 2 internal links
 	assert.Equal(t, 2, externalCount) // 2 external links
+	assert.Equal(t, 0, inaccessibleCount)
 }
 
 func TestDetectLoginForm(t *testing.T) {
@@ -265,31 +275,15 @@ func TestDetectLoginForm(t *testing.T) {
 			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
 			assert.NoError(t, err)
 
-			result := detectLoginForm(doc)
+			result := hasLoginForm(analyzeForms(doc, nil))
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
-func mockHTTPGetSuccess() *http.Response {
-	return newHTTPResponse("<!DOCTYPE html><body>Test Page</body></html>", http.StatusOK)
-}
-
-func newHTTPResponse(response string, statusCode int) *http.Response {
-	body := io.NopCloser(strings.NewReader(response))
-	return &http.Response{
-		StatusCode: statusCode,
-		Body:       body,
-	}
-}
-
-func setupMockHTTP(mockResponse *http.Response, mockError error) func() {
-	HTTPGet = func(url string) (*http.Response, error) {
-		return mockResponse, mockError
-	}
-
-	return func() {
-		HTTPGet = originalHTTPGet
+func newFixtureService(pageUrl string, fixture Fixture) DefaultAnalyzerService {
+	return DefaultAnalyzerService{
+		Fetcher: NewFixtureFetcher(map[string]Fixture{pageUrl: fixture}),
 	}
 }
 
@@ -364,3 +358,74 @@ const validHTMLContentWithoutHeaders = `<!DOCTYPE html>
 </body>
 </html>
 `
+
+func TestAnalyze_ShouldReturnPartialResultOnTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pageUrl := "https://example.com/test-page"
+	html := `<!DOCTYPE html><html><head><title>Slow Links</title></head><body>
+		<a href="https://slow.example.org/">slow external link</a>
+	</body></html>`
+
+	fixtures := NewFixtureFetcher(map[string]Fixture{pageUrl: {Body: html}})
+	d := DefaultAnalyzerService{
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "slow.example.org" {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			}
+			return fixtures.Do(req)
+		}),
+	}
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithTimeout(50*time.Millisecond))
+
+	assert.NoError(t, err)
+	assert.True(t, analyze.TimedOut)
+	assert.Equal(t, "Slow Links", analyze.Title)
+	assert.Equal(t, 1, analyze.ExternalLinks)
+	assert.Equal(t, 0, analyze.InaccessibleLinks)
+	assert.False(t, analyze.HasLoginForm)
+	assert.Equal(t, "Slow Links", analyze.SEO.Title)
+	assert.Equal(t, []model.Issue{
+		{Code: model.IssueCheckTimedOut, Check: CheckLinks, Message: "the check did not finish before the analysis timed out"},
+	}, analyze.Errors)
+	assert.Equal(t, []model.Issue{
+		{Code: model.IssueLinksUnchecked, Check: CheckLinks, Message: "1 link(s) were not checked before the analysis stopped"},
+	}, analyze.Warnings)
+}
+
+func TestAnalyze_ShouldFailWhenContextIsAlreadyCancelled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	d := DefaultAnalyzerService{}
+	_, err := d.Analyze(ctx, "http://127.0.0.1:1/test-page")
+
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAnalyze_ShouldEvaluatePolicy(t *testing.T) {
+	service := newFixtureService("https://example.com", Fixture{Body: `<html><head><title>Policy</title></head><body><h2>No h1</h2></body></html>`})
+	policy := model.Policy{Rules: []model.PolicyRule{
+		{Field: "Title", Op: "notEmpty"},
+		{Name: "has h1", Field: "HeadingCounts.h1", Op: ">=", Value: 1},
+	}}
+
+	result, err := service.Analyze(context.Background(), "https://example.com", WithPolicy(policy))
+
+	assert.NoError(t, err)
+	assert.False(t, result.Policy.Passed)
+	assert.True(t, result.Policy.Results[0].Passed)
+	assert.Equal(t, "HeadingCounts.h1 is missing", result.Policy.Results[1].Explanation)
+}
+
+func TestAnalyze_ShouldRejectInvalidPolicy(t *testing.T) {
+	service := newFixtureService("https://example.com", Fixture{Body: `<html></html>`})
+
+	_, err := service.Analyze(context.Background(), "https://example.com", WithPolicy(model.Policy{Rules: []model.PolicyRule{{Field: "Title", Op: "~"}}}))
+
+	assert.EqualError(t, err, `invalid policy: rule 1 has unknown op "~"`)
+}
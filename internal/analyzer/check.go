@@ -0,0 +1,170 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// Page is the fetched and parsed web page handed to every Check.
+type Page struct {
+	URL      *url.URL
+	Header   http.Header
+	Body     []byte
+	Document *goquery.Document
+	Fetcher  Fetcher
+	Options  Options
+
+	formsOnce sync.Once
+	forms     []Form
+}
+
+// Forms returns the analyzed forms of the page. They are worked out on
+// first use and shared by the checks that need them.
+func (page *Page) Forms() []Form {
+	page.formsOnce.Do(func() {
+		page.forms = analyzeForms(page.Document, page.URL)
+	})
+	return page.forms
+}
+
+// Check is a single analysis run against a Page. The value returned by Run
+// is stored in PageAnalysisResponse.Sections under Name; a nil value adds
+// no section, which is what the built-in checks do since they fill the
+// fixed response fields instead. Checks should stop and return ctx.Err()
+// once ctx is done; the analysis is then returned flagged as timed out. Any
+// other error, or a panic, is reported in the response Errors and the
+// remaining checks still run. Warnings a check appends are attributed to it.
+type Check interface {
+	Name() string
+	Run(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error)
+}
+
+// CheckFunc adapts a plain function to the Check interface.
+type CheckFunc struct {
+	CheckName string
+	Func      func(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error)
+}
+
+func (c CheckFunc) Name() string {
+	return c.CheckName
+}
+
+func (c CheckFunc) Run(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	return c.Func(ctx, page, result)
+}
+
+// Registry holds the checks an analyzer runs, in registration order.
+type Registry struct {
+	mu     sync.RWMutex
+	checks []Check
+}
+
+func NewRegistry(checks ...Check) *Registry {
+	r := &Registry{}
+	for _, check := range checks {
+		r.MustRegister(check)
+	}
+	return r
+}
+
+func (r *Registry) Register(check Check) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.checks {
+		if existing.Name() == check.Name() {
+			return fmt.Errorf("check %q is already registered", check.Name())
+		}
+	}
+	r.checks = append(r.checks, check)
+	return nil
+}
+
+func (r *Registry) MustRegister(check Check) {
+	if err := r.Register(check); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+	for _, check := range r.checks {
+		names = append(names, check.Name())
+	}
+	return names
+}
+
+// Select returns the registered checks to run. An empty enabled list means
+// every check; names in disabled are always skipped. Unknown names are
+// reported as an error so typos in a request don't silently do nothing.
+func (r *Registry) Select(enabled []string, disabled []string) ([]Check, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	known := make(map[string]bool, len(r.checks))
+	for _, check := range r.checks {
+		known[check.Name()] = true
+	}
+	for _, name := range append(append([]string{}, enabled...), disabled...) {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown check: %s", name)
+		}
+	}
+
+	enabledSet := toSet(enabled)
+	disabledSet := toSet(disabled)
+
+	var selected []Check
+	for _, check := range r.checks {
+		if len(enabledSet) > 0 && !enabledSet[check.Name()] {
+			continue
+		}
+		if disabledSet[check.Name()] {
+			continue
+		}
+		selected = append(selected, check)
+	}
+	return selected, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+const (
+	CheckHTMLVersion = "htmlVersion"
+	CheckTitle       = "title"
+	CheckHeadings    = "headings"
+	CheckLinks       = "links"
+	CheckLoginForm   = "loginForm"
+	CheckForms       = "forms"
+	CheckSEO         = "seo"
+)
+
+// DefaultRegistry returns a fresh registry with the built-in checks. The
+// links check is the only one that goes to the network, so it runs last: a
+// deadline it runs into cannot cost the results of the document checks.
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		CheckFunc{CheckName: CheckHTMLVersion, Func: htmlVersionCheck},
+		CheckFunc{CheckName: CheckTitle, Func: titleCheck},
+		CheckFunc{CheckName: CheckHeadings, Func: headingsCheck},
+		CheckFunc{CheckName: CheckLoginForm, Func: loginFormCheck},
+		CheckFunc{CheckName: CheckForms, Func: formsCheck},
+		CheckFunc{CheckName: CheckSEO, Func: seoCheck},
+		CheckFunc{CheckName: CheckLinks, Func: linksCheck},
+	)
+}
@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_RegisterDuplicateName(t *testing.T) {
+	r := DefaultRegistry()
+
+	err := r.Register(CheckFunc{CheckName: CheckTitle, Func: titleCheck})
+
+	assert.Error(t, err)
+	assert.Equal(t, `check "title" is already registered`, err.Error())
+}
+
+func TestRegistry_Select(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  []string
+		disabled []string
+		expected []string
+	}{
+		{
+			name:     "All checks by default",
+			expected: []string{CheckHTMLVersion, CheckTitle, CheckHeadings, CheckLoginForm, CheckForms, CheckSEO, CheckLinks},
+		},
+		{
+			name:     "Only enabled checks",
+			enabled:  []string{CheckLoginForm, CheckTitle},
+			expected: []string{CheckTitle, CheckLoginForm},
+		},
+		{
+			name:     "Disabled checks are skipped",
+			disabled: []string{CheckLinks},
+			expected: []string{CheckHTMLVersion, CheckTitle, CheckHeadings, CheckLoginForm, CheckForms, CheckSEO},
+		},
+		{
+			name:     "Disabled wins over enabled",
+			enabled:  []string{CheckTitle, CheckLinks},
+			disabled: []string{CheckLinks},
+			expected: []string{CheckTitle},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := DefaultRegistry().Select(tt.enabled, tt.disabled)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, check := range checks {
+				names = append(names, check.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestRegistry_SelectUnknownCheck(t *testing.T) {
+	_, err := DefaultRegistry().Select([]string{"wordCount"}, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, "unknown check: wordCount", err.Error())
+}
+
+func TestAnalyze_CustomCheckAddsSection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pageUrl := "https://example.com/test-page"
+
+	registry := DefaultRegistry()
+	registry.MustRegister(CheckFunc{
+		CheckName: "sectionCount",
+		Func: func(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+			return page.Document.Find("section").Length(), nil
+		},
+	})
+
+	d := newFixtureService(pageUrl, Fixture{Body: validHTMLContentWithHeaders})
+	d.Registry = registry
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithChecks(CheckTitle, "sectionCount"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Example Page with Various Links", analyze.Title)
+	assert.Equal(t, 2, analyze.Sections["sectionCount"])
+	assert.Equal(t, "", analyze.HTMLVersion)
+	assert.Equal(t, 0, analyze.ExternalLinks)
+}
+
+func TestAnalyze_UnknownCheckIsRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	d := DefaultAnalyzerService{}
+	_, err := d.Analyze(context.Background(), "https://example.com/test-page", WithoutChecks("wordCount"))
+
+	assert.Error(t, err)
+	assert.Equal(t, "unknown check: wordCount", err.Error())
+}
+
+func TestAnalyze_FailedCheckKeepsOtherResults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pageUrl := "https://example.com/test-page"
+
+	registry := NewRegistry(
+		CheckFunc{CheckName: CheckTitle, Func: titleCheck},
+		CheckFunc{
+			CheckName: "failing",
+			Func: func(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+				result.Warnings = append(result.Warnings, Issue{Code: "partial", Message: "half done"})
+				return nil, errors.New("tokenizer failed")
+			},
+		},
+		CheckFunc{
+			CheckName: "panicking",
+			Func: func(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+				panic("nil map")
+			},
+		},
+		CheckFunc{CheckName: CheckHeadings, Func: headingsCheck},
+	)
+	d := newFixtureService(pageUrl, Fixture{Body: validHTMLContentWithHeaders})
+	d.Registry = registry
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Example Page with Various Links", analyze.Title)
+	assert.NotEmpty(t, analyze.HeadingCounts)
+	assert.False(t, analyze.TimedOut)
+	assert.Equal(t, []Issue{{Code: "partial", Check: "failing", Message: "half done"}}, analyze.Warnings)
+	assert.Equal(t, []Issue{
+		{Code: IssueCheckFailed, Check: "failing", Message: "tokenizer failed"},
+		{Code: IssueCheckFailed, Check: "panicking", Message: "check panicked: nil map"},
+	}, analyze.Errors)
+}
@@ -0,0 +1,281 @@
+package analyzer
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"golang.org/x/net/html"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// publicIDPattern splits formal public identifiers such as
+// "-//W3C//DTD XHTML Basic 1.1//EN" or "-//W3C//DTD HTML 4.01 Transitional//EN".
+var publicIDPattern = regexp.MustCompile(`(?i)^-//[^/]*//DTD (XHTML|HTML)(\+RDFa| Basic| Mobile)?(?: ([0-9]+(?:\.[0-9]+)?))?((?: [^/]+)?)//`)
+
+// dtdURLs are the published DTDs of the versions whose pages usually name
+// them, keyed by family, version and variant.
+var dtdURLs = map[string]string{
+	"HTML 4.01 Strict":       "http://www.w3.org/TR/html4/strict.dtd",
+	"HTML 4.01 Transitional": "http://www.w3.org/TR/html4/loose.dtd",
+	"HTML 4.01 Frameset":     "http://www.w3.org/TR/html4/frameset.dtd",
+	"HTML 4.0 Strict":        "http://www.w3.org/TR/REC-html40/strict.dtd",
+	"HTML 4.0 Transitional":  "http://www.w3.org/TR/REC-html40/loose.dtd",
+	"HTML 4.0 Frameset":      "http://www.w3.org/TR/REC-html40/frameset.dtd",
+	"XHTML 1.0 Strict":       "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd",
+	"XHTML 1.0 Transitional": "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd",
+	"XHTML 1.0 Frameset":     "http://www.w3.org/TR/xhtml1/DTD/xhtml1-frameset.dtd",
+	"XHTML 1.1":              "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd",
+	"XHTML 1.0 Basic":        "http://www.w3.org/TR/xhtml-basic/xhtml-basic10.dtd",
+	"XHTML 1.1 Basic":        "http://www.w3.org/TR/xhtml-basic/xhtml-basic11.dtd",
+	"XHTML 1.0 Mobile":       "http://www.wapforum.org/DTD/xhtml-mobile10.dtd",
+	"XHTML 1.1 Mobile":       "http://www.openmobilealliance.org/tech/DTD/xhtml-mobile11.dtd",
+	"XHTML 1.2 Mobile":       "http://www.openmobilealliance.org/tech/DTD/xhtml-mobile12.dtd",
+	"XHTML 1.0 RDFa":         "http://www.w3.org/MarkUp/DTD/xhtml-rdfa-1.dtd",
+}
+
+// detectHTMLVersion names the HTML version declared by the doctype of body,
+// e.g. "HTML5", "HTML 4.01" or "XHTML Basic 1.1", or "Unknown".
+func detectHTMLVersion(body []byte) string {
+	return htmlVersionLabel(parseDoctype(body))
+}
+
+func htmlVersionLabel(doctype Doctype) string {
+	switch {
+	case doctype.Family == DoctypeFamilyUnknown:
+		return "Unknown"
+	case doctype.Family == DoctypeFamilyHTML && doctype.Version == "5":
+		return "HTML5"
+	}
+	label := doctype.Family
+	switch doctype.Variant {
+	case "Basic", "Mobile":
+		label += " " + doctype.Variant
+	case "RDFa":
+		label += "+RDFa"
+	}
+	if doctype.Version != "" {
+		label += " " + doctype.Version
+	}
+	return label
+}
+
+// parseDoctype reads the doctype from the raw tokens of body. Only a
+// doctype before any other content counts, as in browsers.
+func parseDoctype(body []byte) Doctype {
+	z := html.NewTokenizer(bytes.NewReader(bytes.TrimPrefix(body, utf8BOM)))
+	for {
+		switch z.Next() {
+		case html.CommentToken:
+			continue
+		case html.TextToken:
+			if len(bytes.TrimSpace(z.Raw())) == 0 {
+				continue
+			}
+		case html.DoctypeToken:
+			raw := string(z.Raw())
+			doctype := parseDoctypeDeclaration(string(z.Text()))
+			doctype.Raw = raw
+			return doctype
+		}
+		return Doctype{Family: DoctypeFamilyUnknown, RenderingMode: RenderingQuirks, Missing: true}
+	}
+}
+
+// parseDoctypeDeclaration parses what follows "<!DOCTYPE": a name and
+// optional PUBLIC or SYSTEM identifiers.
+func parseDoctypeDeclaration(declaration string) Doctype {
+	doctype := Doctype{Family: DoctypeFamilyUnknown}
+	var hasPublic, hasSystem, forceQuirks bool
+
+	rest := strings.TrimLeft(declaration, " \t\n\f\r")
+	name, rest := nextField(rest)
+	doctype.Name = strings.ToLower(name)
+	if doctype.Name == "" {
+		forceQuirks = true
+	}
+
+	rest = strings.TrimLeft(rest, " \t\n\f\r")
+	keyword := ""
+	if len(rest) >= 6 {
+		keyword = strings.ToUpper(rest[:6])
+	}
+	switch {
+	case rest == "":
+	case keyword == "PUBLIC" || keyword == "SYSTEM":
+		rest = strings.TrimLeft(rest[6:], " \t\n\f\r")
+		var ok bool
+		if keyword == "PUBLIC" {
+			if doctype.PublicID, rest, ok = quoted(rest); !ok {
+				forceQuirks = true
+				break
+			}
+			hasPublic = true
+			if rest == "" {
+				break
+			}
+		}
+		if doctype.SystemID, rest, ok = quoted(rest); !ok {
+			forceQuirks = true
+			break
+		}
+		hasSystem = true
+		if rest != "" {
+			doctype.Malformed = true
+		}
+	default:
+		forceQuirks = true
+	}
+	doctype.Malformed = doctype.Malformed || forceQuirks
+
+	if doctype.Name == "html" && !forceQuirks {
+		classifyDoctype(&doctype, hasPublic, hasSystem)
+	}
+	doctype.RenderingMode = renderingMode(doctype, hasSystem, forceQuirks)
+	return doctype
+}
+
+func classifyDoctype(doctype *Doctype, hasPublic bool, hasSystem bool) {
+	if !hasPublic && (!hasSystem || doctype.SystemID == "about:legacy-compat") {
+		doctype.Family, doctype.Version = DoctypeFamilyHTML, "5"
+		return
+	}
+	match := publicIDPattern.FindStringSubmatch(doctype.PublicID)
+	if match == nil {
+		return
+	}
+	doctype.Family = strings.ToUpper(match[1])
+	doctype.Version = match[3]
+	switch profile := strings.TrimSpace(match[2]); {
+	case strings.EqualFold(profile, "+RDFa"):
+		doctype.Variant = "RDFa"
+	case profile != "":
+		doctype.Variant = strings.ToUpper(profile[:1]) + strings.ToLower(profile[1:])
+	default:
+		for _, word := range strings.Fields(match[4]) {
+			for _, variant := range []string{"Strict", "Transitional", "Frameset"} {
+				if strings.EqualFold(word, variant) {
+					doctype.Variant = variant
+				}
+			}
+		}
+		if doctype.Variant == "" && doctype.Family == DoctypeFamilyHTML && strings.HasPrefix(doctype.Version, "4") {
+			doctype.Variant = "Strict"
+		}
+	}
+	doctype.DTDURL = doctype.SystemID
+	if doctype.DTDURL == "" {
+		doctype.DTDURL = dtdURLs[strings.TrimSpace(doctype.Family+" "+doctype.Version+" "+doctype.Variant)]
+	}
+}
+
+func nextField(s string) (string, string) {
+	end := strings.IndexAny(s, " \t\n\f\r")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// quoted reads a single or double quoted identifier at the start of s and
+// returns it with the rest of s after any whitespace.
+func quoted(s string) (string, string, bool) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", s, false
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return s[1:], "", false
+	}
+	return s[1 : end+1], strings.TrimLeft(s[end+2:], " \t\n\f\r"), true
+}
+
+// quirksPublicIDPrefixes start the public identifiers that put browsers in
+// quirks mode, per the HTML standard.
+var quirksPublicIDPrefixes = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
+
+// renderingMode applies the quirks mode rules of the HTML standard.
+func renderingMode(doctype Doctype, hasSystem bool, forceQuirks bool) string {
+	publicID := strings.ToLower(doctype.PublicID)
+	systemID := strings.ToLower(doctype.SystemID)
+	transitionalOrFrameset := strings.HasPrefix(publicID, "-//w3c//dtd html 4.01 frameset//") ||
+		strings.HasPrefix(publicID, "-//w3c//dtd html 4.01 transitional//")
+
+	if forceQuirks || doctype.Name != "html" ||
+		publicID == "-//w3o//dtd w3 html strict 3.0//en//" ||
+		publicID == "-/w3c/dtd html 4.0 transitional/en" ||
+		publicID == "html" ||
+		systemID == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd" ||
+		(!hasSystem && transitionalOrFrameset) {
+		return RenderingQuirks
+	}
+	for _, prefix := range quirksPublicIDPrefixes {
+		if strings.HasPrefix(publicID, prefix) {
+			return RenderingQuirks
+		}
+	}
+	if strings.HasPrefix(publicID, "-//w3c//dtd xhtml 1.0 frameset//") ||
+		strings.HasPrefix(publicID, "-//w3c//dtd xhtml 1.0 transitional//") ||
+		transitionalOrFrameset {
+		return RenderingLimitedQuirks
+	}
+	return RenderingStandards
+}
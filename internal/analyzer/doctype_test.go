@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"testing"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDoctype(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected Doctype
+	}{
+		{
+			name: "HTML5",
+			html: "<!DOCTYPE html><html></html>",
+			expected: Doctype{
+				Raw: "<!DOCTYPE html>", Name: "html",
+				Family: DoctypeFamilyHTML, Version: "5", RenderingMode: RenderingStandards,
+			},
+		},
+		{
+			name: "HTML5 legacy compat",
+			html: "<!DOCTYPE html SYSTEM 'about:legacy-compat'><html></html>",
+			expected: Doctype{
+				Raw: "<!DOCTYPE html SYSTEM 'about:legacy-compat'>", Name: "html", SystemID: "about:legacy-compat",
+				Family: DoctypeFamilyHTML, Version: "5", RenderingMode: RenderingStandards,
+			},
+		},
+		{
+			name: "HTML 4.01 Strict without system identifier",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN"><html></html>`,
+			expected: Doctype{
+				Raw: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN">`, Name: "html", PublicID: "-//W3C//DTD HTML 4.01//EN",
+				Family: DoctypeFamilyHTML, Version: "4.01", Variant: "Strict",
+				DTDURL: "http://www.w3.org/TR/html4/strict.dtd", RenderingMode: RenderingStandards,
+			},
+		},
+		{
+			name: "HTML 4.01 Transitional with system identifier",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`,
+			expected: Doctype{
+				Raw:  `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`,
+				Name: "html", PublicID: "-//W3C//DTD HTML 4.01 Transitional//EN", SystemID: "http://www.w3.org/TR/html4/loose.dtd",
+				Family: DoctypeFamilyHTML, Version: "4.01", Variant: "Transitional",
+				DTDURL: "http://www.w3.org/TR/html4/loose.dtd", RenderingMode: RenderingLimitedQuirks,
+			},
+		},
+		{
+			name: "HTML 4.01 Frameset without system identifier",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN">`,
+			expected: Doctype{
+				Raw:  `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN">`,
+				Name: "html", PublicID: "-//W3C//DTD HTML 4.01 Frameset//EN",
+				Family: DoctypeFamilyHTML, Version: "4.01", Variant: "Frameset",
+				DTDURL: "http://www.w3.org/TR/html4/frameset.dtd", RenderingMode: RenderingQuirks,
+			},
+		},
+		{
+			name: "XHTML 1.0 Strict",
+			html: `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`,
+			expected: Doctype{
+				Raw:  `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`,
+				Name: "html", PublicID: "-//W3C//DTD XHTML 1.0 Strict//EN", SystemID: "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd",
+				Family: DoctypeFamilyXHTML, Version: "1.0", Variant: "Strict",
+				DTDURL: "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd", RenderingMode: RenderingStandards,
+			},
+		},
+		{
+			name: "XHTML 1.0 Transitional",
+			html: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN">`,
+			expected: Doctype{
+				Raw:  `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN">`,
+				Name: "html", PublicID: "-//W3C//DTD XHTML 1.0 Transitional//EN",
+				Family: DoctypeFamilyXHTML, Version: "1.0", Variant: "Transitional",
+				DTDURL: "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd", RenderingMode: RenderingLimitedQuirks,
+			},
+		},
+		{
+			name: "XHTML 1.1",
+			html: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd">`,
+			expected: Doctype{
+				Raw:  `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd">`,
+				Name: "html", PublicID: "-//W3C//DTD XHTML 1.1//EN", SystemID: "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd",
+				Family: DoctypeFamilyXHTML, Version: "1.1",
+				DTDURL: "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd", RenderingMode: RenderingStandards,
+			},
+		},
+		{
+			name: "XHTML+RDFa",
+			html: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML+RDFa 1.0//EN">`,
+			expected: Doctype{
+				Raw:  `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML+RDFa 1.0//EN">`,
+				Name: "html", PublicID: "-//W3C//DTD XHTML+RDFa 1.0//EN",
+				Family: DoctypeFamilyXHTML, Version: "1.0", Variant: "RDFa",
+				DTDURL: "http://www.w3.org/MarkUp/DTD/xhtml-rdfa-1.dtd", RenderingMode: RenderingStandards,
+			},
+		},
+		{
+			name: "HTML 3.2 is quirks",
+			html: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`,
+			expected: Doctype{
+				Raw:  `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`,
+				Name: "html", PublicID: "-//W3C//DTD HTML 3.2 Final//EN",
+				Family: DoctypeFamilyHTML, Version: "3.2", RenderingMode: RenderingQuirks,
+			},
+		},
+		{
+			name: "Missing",
+			html: "<html><body><p>&lt;!DOCTYPE html&gt;</p></body></html>",
+			expected: Doctype{
+				Family: DoctypeFamilyUnknown, RenderingMode: RenderingQuirks, Missing: true,
+			},
+		},
+		{
+			name: "After content",
+			html: "<p>hello</p><!DOCTYPE html>",
+			expected: Doctype{
+				Family: DoctypeFamilyUnknown, RenderingMode: RenderingQuirks, Missing: true,
+			},
+		},
+		{
+			name: "Other name",
+			html: "<!DOCTYPE something-custom>",
+			expected: Doctype{
+				Raw: "<!DOCTYPE something-custom>", Name: "something-custom",
+				Family: DoctypeFamilyUnknown, RenderingMode: RenderingQuirks,
+			},
+		},
+		{
+			name: "Without name",
+			html: "<!DOCTYPE>",
+			expected: Doctype{
+				Raw: "<!DOCTYPE>", Family: DoctypeFamilyUnknown, RenderingMode: RenderingQuirks, Malformed: true,
+			},
+		},
+		{
+			name: "Unquoted public identifier",
+			html: "<!DOCTYPE html PUBLIC -//W3C//DTD HTML 4.01//EN>",
+			expected: Doctype{
+				Raw: "<!DOCTYPE html PUBLIC -//W3C//DTD HTML 4.01//EN>", Name: "html",
+				Family: DoctypeFamilyUnknown, RenderingMode: RenderingQuirks, Malformed: true,
+			},
+		},
+		{
+			name: "Unknown keyword",
+			html: `<!DOCTYPE html FOO "bar">`,
+			expected: Doctype{
+				Raw: `<!DOCTYPE html FOO "bar">`, Name: "html",
+				Family: DoctypeFamilyUnknown, RenderingMode: RenderingQuirks, Malformed: true,
+			},
+		},
+		{
+			name: "Trailing junk after system identifier",
+			html: `<!DOCTYPE html SYSTEM "about:legacy-compat" junk>`,
+			expected: Doctype{
+				Raw: `<!DOCTYPE html SYSTEM "about:legacy-compat" junk>`, Name: "html", SystemID: "about:legacy-compat",
+				Family: DoctypeFamilyHTML, Version: "5", RenderingMode: RenderingStandards, Malformed: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseDoctype([]byte(tt.html)))
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
+)
+
+// Error codes identify why an analysis failed. They are part of the API and
+// do not change.
+const (
+	CodeInvalidInput   = "invalid_input"
+	CodeBlockedTarget  = netguard.Code
+	CodeDNSFailure     = "dns_failure"
+	CodeFetchFailed    = "fetch_failed"
+	CodeTimeout        = "timeout"
+	CodeCanceled       = "canceled"
+	CodeUpstreamStatus = "upstream_status"
+	CodeNonHTMLContent = "non_html_content"
+	CodeBodyTooLarge   = "body_too_large"
+	CodeInternal       = "internal_error"
+)
+
+// Error is an analysis failure with a stable Code. UpstreamStatus is the
+// HTTP status of the page for CodeUpstreamStatus; Redirects is the chain
+// followed before the redirects failed.
+type Error struct {
+	Code           string
+	Message        string
+	UpstreamStatus int
+	Redirects      *RedirectChain
+	Err            error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(code string, err error, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// ErrorCode returns the code of err. Errors that were not raised by the
+// analyzer are classified by what they wrap, falling back to CodeInternal.
+func ErrorCode(err error) string {
+	var analysisErr *Error
+	var blocked *netguard.BlockedError
+	var dnsErr *net.DNSError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &analysisErr):
+		return analysisErr.Code
+	case errors.As(err, &blocked):
+		return CodeBlockedTarget
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.As(err, &dnsErr):
+		return CodeDNSFailure
+	}
+	return CodeInternal
+}
+
+// fetchError classifies an error of fetchPage, keeping the redirects it
+// followed when they were the problem.
+func fetchError(ctx context.Context, err error, redirects *RedirectChain) *Error {
+	var blocked *netguard.BlockedError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case ctx.Err() != nil:
+		return newError(contextErrorCode(ctx), ctx.Err(), "failed to fetch the webpage: %v", ctx.Err())
+	case errors.As(err, &blocked):
+		return newError(CodeBlockedTarget, blocked, "failed to fetch the webpage: %v", blocked)
+	case errors.Is(err, ErrRedirectLoop), errors.Is(err, ErrTooManyRedirects), errors.Is(err, ErrRedirectScheme):
+		failure := newError(CodeFetchFailed, err, "failed to fetch the webpage: %v", err)
+		failure.Redirects = redirects
+		return failure
+	case errors.As(err, &dnsErr):
+		return newError(CodeDNSFailure, err, "failed to fetch the webpage: could not resolve %s", dnsErr.Name)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return newError(CodeTimeout, err, "failed to fetch the webpage: timed out")
+	}
+	return newError(CodeFetchFailed, err, "failed to fetch the webpage")
+}
+
+// contextErrorCode tells a client that went away from an analysis that ran
+// out of time.
+func contextErrorCode(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return CodeCanceled
+	}
+	return CodeTimeout
+}
@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze_ErrorCodes(t *testing.T) {
+	pageUrl := "https://example.com/page"
+	html := http.Header{"Content-Type": []string{"text/html; charset=utf-8"}}
+
+	tests := []struct {
+		name    string
+		service DefaultAnalyzerService
+		url     string
+		options []Option
+		code    string
+		message string
+	}{
+		{
+			name:    "invalid URL",
+			service: DefaultAnalyzerService{},
+			url:     "invalid-url",
+			code:    CodeInvalidInput,
+			message: "invalid URL format",
+		},
+		{
+			name:    "unknown check",
+			service: newFixtureService(pageUrl, Fixture{Body: "<title>x</title>"}),
+			url:     pageUrl,
+			options: []Option{WithChecks("wordCount")},
+			code:    CodeInvalidInput,
+			message: "unknown check: wordCount",
+		},
+		{
+			name:    "invalid policy",
+			service: newFixtureService(pageUrl, Fixture{Body: "<title>x</title>"}),
+			url:     pageUrl,
+			options: []Option{WithPolicy(model.Policy{Rules: []model.PolicyRule{{Field: "title", Op: "~"}}})},
+			code:    CodeInvalidInput,
+			message: `invalid policy: rule 1 has unknown op "~"`,
+		},
+		{
+			name:    "dns failure",
+			service: newFixtureService(pageUrl, Fixture{Err: &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}}),
+			url:     pageUrl,
+			code:    CodeDNSFailure,
+			message: "failed to fetch the webpage: could not resolve example.com",
+		},
+		{
+			name: "blocked target",
+			service: newFixtureService(pageUrl, Fixture{Err: &netguard.BlockedError{
+				Address: netip.MustParseAddr("127.0.0.1"),
+				Range:   netip.MustParsePrefix("127.0.0.0/8"),
+			}}),
+			url:     pageUrl,
+			code:    CodeBlockedTarget,
+			message: "failed to fetch the webpage: target address 127.0.0.1 is not allowed (127.0.0.0/8)",
+		},
+		{
+			name:    "connection failure",
+			service: newFixtureService(pageUrl, Fixture{Err: errors.New("connection refused")}),
+			url:     pageUrl,
+			code:    CodeFetchFailed,
+			message: "failed to fetch the webpage",
+		},
+		{
+			name:    "upstream status",
+			service: newFixtureService(pageUrl, Fixture{StatusCode: http.StatusInternalServerError}),
+			url:     pageUrl,
+			code:    CodeUpstreamStatus,
+			message: "failed to fetch the webpage, status code: 500 Internal Server Error",
+		},
+		{
+			name:    "non HTML content",
+			service: newFixtureService(pageUrl, Fixture{Header: http.Header{"Content-Type": []string{"application/pdf"}}, Body: "%PDF-1.4"}),
+			url:     pageUrl,
+			code:    CodeNonHTMLContent,
+			message: "the webpage is not HTML: application/pdf",
+		},
+		{
+			name: "body too large",
+			service: DefaultAnalyzerService{
+				Fetcher:           NewFixtureFetcher(map[string]Fixture{pageUrl: {Header: html, Body: "<title>too long</title>"}}),
+				MaxBodyBytes:      8,
+				RejectLargeBodies: true,
+			},
+			url:     pageUrl,
+			code:    CodeBodyTooLarge,
+			message: "the webpage is larger than 8 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.service.Analyze(context.Background(), tt.url, tt.options...)
+
+			var analysisErr *Error
+			assert.ErrorAs(t, err, &analysisErr)
+			assert.Equal(t, tt.code, analysisErr.Code)
+			assert.Equal(t, tt.code, ErrorCode(err))
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+func TestAnalyze_UpstreamStatusIsReported(t *testing.T) {
+	d := DefaultAnalyzerService{Fetcher: NewFixtureFetcher(nil)}
+
+	_, err := d.Analyze(context.Background(), "https://example.com/404")
+
+	var analysisErr *Error
+	assert.ErrorAs(t, err, &analysisErr)
+	assert.Equal(t, http.StatusNotFound, analysisErr.UpstreamStatus)
+}
+
+func TestAnalyze_TimeoutBeforeFetch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	d := newFixtureService("https://example.com", Fixture{Err: context.DeadlineExceeded})
+
+	_, err := d.Analyze(ctx, "https://example.com")
+
+	assert.Equal(t, CodeTimeout, ErrorCode(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAnalyze_CanceledBeforeFetch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d := newFixtureService("https://example.com", Fixture{Err: context.Canceled})
+
+	_, err := d.Analyze(ctx, "https://example.com")
+
+	assert.Equal(t, CodeCanceled, ErrorCode(err))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestErrorCode(t *testing.T) {
+	blocked := &netguard.BlockedError{Address: netip.MustParseAddr("10.0.0.1"), Range: netip.MustParsePrefix("10.0.0.0/8")}
+
+	assert.Equal(t, "", ErrorCode(nil))
+	assert.Equal(t, CodeUpstreamStatus, ErrorCode(fmt.Errorf("wrapped: %w", &Error{Code: CodeUpstreamStatus})))
+	assert.Equal(t, CodeBlockedTarget, ErrorCode(fmt.Errorf("failed: %w", blocked)))
+	assert.Equal(t, CodeTimeout, ErrorCode(fmt.Errorf("failed: %w", context.DeadlineExceeded)))
+	assert.Equal(t, CodeCanceled, ErrorCode(fmt.Errorf("failed: %w", context.Canceled)))
+	assert.Equal(t, CodeDNSFailure, ErrorCode(&net.DNSError{Name: "example.invalid"}))
+	assert.Equal(t, CodeInternal, ErrorCode(errors.New("boom")))
+}
@@ -0,0 +1,299 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
+)
+
+// Fetcher performs the requests of an analysis: the GET of the analyzed page
+// and the requests made by the link checks. It has the same shape as
+// http.Client.Do so a client can be plugged in directly.
+type Fetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// FetcherFunc adapts a plain function to the Fetcher interface.
+type FetcherFunc func(req *http.Request) (*http.Response, error)
+
+func (f FetcherFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// HTTPFetcher fetches http and https URLs with Client, or with
+// http.DefaultClient when Client is nil. Redirects are returned instead of
+// followed; the analyzer follows them itself to record the chain.
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+func (f HTTPFetcher) Do(req *http.Request) (*http.Response, error) {
+	client := http.Client{}
+	if f.Client != nil {
+		client = *f.Client
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client.Do(req)
+}
+
+// HTTPConfig bounds the phases of an HTTP request. Timeout covers the whole
+// exchange including reading the body; zero values mean no limit. A Guard
+// restricts which addresses may be dialed; proxies are not used with a
+// Guard since it would only see the proxy address.
+type HTTPConfig struct {
+	ConnectTimeout        time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	Timeout               time.Duration
+	Guard                 *netguard.Guard
+}
+
+var DefaultHTTPConfig = HTTPConfig{
+	ConnectTimeout:        5 * time.Second,
+	TLSHandshakeTimeout:   5 * time.Second,
+	ResponseHeaderTimeout: 10 * time.Second,
+	Timeout:               30 * time.Second,
+}
+
+func NewHTTPFetcher(config HTTPConfig) HTTPFetcher {
+	dialer := &net.Dialer{
+		Timeout:   config.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	proxy := http.ProxyFromEnvironment
+	if config.Guard != nil {
+		dialer.Control = config.Guard.Control
+		proxy = nil
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   8,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	return HTTPFetcher{
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+	}
+}
+
+func (f HTTPFetcher) Schemes() []string {
+	return []string{"http", "https"}
+}
+
+// FileFetcher serves file:// URLs from the local file system. A missing file
+// is answered with a 404 response rather than an error, like a web server.
+type FileFetcher struct{}
+
+func (f FileFetcher) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "file" {
+		return nil, fmt.Errorf("file fetcher cannot fetch %s URLs", req.URL.Scheme)
+	}
+	path := req.URL.Path
+	if path == "" {
+		path = req.URL.Opaque
+	}
+	content, err := os.ReadFile(filepath.FromSlash(path))
+	if os.IsNotExist(err) {
+		return newResponse(req, http.StatusNotFound, nil, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return newResponse(req, http.StatusOK, header, content), nil
+}
+
+func (f FileFetcher) Schemes() []string {
+	return []string{"file"}
+}
+
+// DataFetcher serves RFC 2397 data: URLs.
+type DataFetcher struct{}
+
+func (f DataFetcher) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "data" {
+		return nil, fmt.Errorf("data fetcher cannot fetch %s URLs", req.URL.Scheme)
+	}
+	mediaType, content, err := parseDataURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", mediaType)
+	return newResponse(req, http.StatusOK, header, content), nil
+}
+
+func (f DataFetcher) Schemes() []string {
+	return []string{"data"}
+}
+
+func parseDataURL(dataURL *url.URL) (string, []byte, error) {
+	raw := dataURL.Opaque
+	if dataURL.RawQuery != "" || dataURL.ForceQuery {
+		raw += "?" + dataURL.RawQuery
+	}
+	meta, data, found := strings.Cut(raw, ",")
+	if !found {
+		return "", nil, fmt.Errorf("invalid data URL: missing comma")
+	}
+
+	isBase64 := strings.HasSuffix(strings.ToLower(meta), ";base64")
+	if isBase64 {
+		meta = meta[:len(meta)-len(";base64")]
+	}
+	if meta == "" {
+		meta = "text/plain;charset=US-ASCII"
+	}
+
+	decoded, err := url.PathUnescape(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data URL: %w", err)
+	}
+	if !isBase64 {
+		return meta, []byte(decoded), nil
+	}
+	content, err := base64.StdEncoding.DecodeString(decoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data URL: %w", err)
+	}
+	return meta, content, nil
+}
+
+// Fixture is a canned response served by a FixtureFetcher. A zero StatusCode
+// means 200 OK; a non-nil Err is returned instead of a response.
+type Fixture struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+	Err        error
+}
+
+// FixtureFetcher serves in-memory fixtures keyed by URL. Unknown URLs get a
+// 404 response.
+type FixtureFetcher struct {
+	Fixtures map[string]Fixture
+}
+
+func NewFixtureFetcher(fixtures map[string]Fixture) FixtureFetcher {
+	return FixtureFetcher{Fixtures: fixtures}
+}
+
+func (f FixtureFetcher) Do(req *http.Request) (*http.Response, error) {
+	fixture, ok := f.Fixtures[req.URL.String()]
+	if !ok {
+		return newResponse(req, http.StatusNotFound, nil, nil), nil
+	}
+	if fixture.Err != nil {
+		return nil, fixture.Err
+	}
+	statusCode := fixture.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	return newResponse(req, statusCode, fixture.Header.Clone(), []byte(fixture.Body)), nil
+}
+
+func (f FixtureFetcher) Schemes() []string {
+	seen := map[string]bool{"http": true, "https": true}
+	for rawURL := range f.Fixtures {
+		if parsed, err := url.Parse(rawURL); err == nil && parsed.Scheme != "" {
+			seen[parsed.Scheme] = true
+		}
+	}
+	schemes := make([]string, 0, len(seen))
+	for scheme := range seen {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// SchemeFetcher routes each request to the Fetcher registered for its URL
+// scheme.
+type SchemeFetcher map[string]Fetcher
+
+// NewLocalFetcher supports http, https, file and data URLs. It reads the local
+// file system, so it is meant for the command line and tests rather than for
+// a network-facing server. Only the analyzed URL itself can be a file or
+// data URL: links are checked over http(s) only and http(s) pages cannot
+// redirect to other schemes.
+func NewLocalFetcher() SchemeFetcher {
+	httpFetcher := NewHTTPFetcher(DefaultHTTPConfig)
+	return SchemeFetcher{
+		"http":  httpFetcher,
+		"https": httpFetcher,
+		"file":  FileFetcher{},
+		"data":  DataFetcher{},
+	}
+}
+
+func (f SchemeFetcher) Do(req *http.Request) (*http.Response, error) {
+	fetcher, ok := f[req.URL.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("unsupported URL scheme: %s", req.URL.Scheme)
+	}
+	return fetcher.Do(req)
+}
+
+func (f SchemeFetcher) Schemes() []string {
+	schemes := make([]string, 0, len(f))
+	for scheme := range f {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// supportedSchemes returns the URL schemes fetcher accepts. Fetchers that do
+// not say are assumed to speak plain http and https.
+func supportedSchemes(fetcher Fetcher) []string {
+	if s, ok := fetcher.(interface{ Schemes() []string }); ok {
+		return s.Schemes()
+	}
+	return []string{"http", "https"}
+}
+
+func newResponse(req *http.Request, statusCode int, header http.Header, body []byte) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	if req.Method == http.MethodHead {
+		body = nil
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
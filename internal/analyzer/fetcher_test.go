@@ -0,0 +1,315 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataFetcher(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "Plain text",
+			url:         "data:text/html,%3Ch1%3EHi%3C%2Fh1%3E",
+			contentType: "text/html",
+			body:        "<h1>Hi</h1>",
+		},
+		{
+			name:        "Base64",
+			url:         "data:text/html;base64,PGgxPkhpPC9oMT4=",
+			contentType: "text/html",
+			body:        "<h1>Hi</h1>",
+		},
+		{
+			name:        "Default media type",
+			url:         "data:,hello",
+			contentType: "text/plain;charset=US-ASCII",
+			body:        "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doGet(t, DataFetcher{}, tt.url)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			assert.Equal(t, tt.body, readBody(t, resp))
+		})
+	}
+}
+
+func TestFileFetcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.html")
+	assert.NoError(t, os.WriteFile(path, []byte("<title>Local</title>"), 0o600))
+
+	resp := doGet(t, FileFetcher{}, "file://"+filepath.ToSlash(path))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "<title>Local</title>", readBody(t, resp))
+
+	resp = doGet(t, FileFetcher{}, "file://"+filepath.ToSlash(filepath.Join(dir, "missing.html")))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestSchemeFetcher_UnsupportedScheme(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "ftp://example.com/file", nil)
+	assert.NoError(t, err)
+
+	_, err = SchemeFetcher{"data": DataFetcher{}}.Do(req)
+
+	assert.Error(t, err)
+	assert.Equal(t, "unsupported URL scheme: ftp", err.Error())
+}
+
+func TestAnalyze_DataURLWithLocalFetcher(t *testing.T) {
+	d := DefaultAnalyzerService{Fetcher: NewLocalFetcher()}
+	analyze, err := d.Analyze(context.Background(), "data:text/html,%3Ctitle%3EInline%3C%2Ftitle%3E")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Inline", analyze.Title)
+}
+
+func TestAnalyze_DataURLRejectedByDefaultFetcher(t *testing.T) {
+	d := DefaultAnalyzerService{}
+	_, err := d.Analyze(context.Background(), "data:text/html,%3Ctitle%3EInline%3C%2Ftitle%3E")
+
+	assert.Error(t, err)
+	assert.Equal(t, "invalid URL format", err.Error())
+}
+
+func TestAnalyze_IndependentFetchersInOneProcess(t *testing.T) {
+	pageUrl := "https://example.com/"
+	first := newFixtureService(pageUrl, Fixture{Body: "<title>First</title>"})
+	second := newFixtureService(pageUrl, Fixture{Body: "<title>Second</title>"})
+
+	titles := make(chan string, 2)
+	for _, d := range []DefaultAnalyzerService{first, second} {
+		go func(d DefaultAnalyzerService) {
+			analyze, _ := d.Analyze(context.Background(), pageUrl)
+			titles <- analyze.Title
+		}(d)
+	}
+
+	assert.ElementsMatch(t, []string{"First", "Second"}, []string{<-titles, <-titles})
+}
+
+func doGet(t *testing.T, fetcher Fetcher, rawURL string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	assert.NoError(t, err)
+	resp, err := fetcher.Do(req)
+	assert.NoError(t, err)
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestHTTPFetcher_ResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher(HTTPConfig{ResponseHeaderTimeout: 20 * time.Millisecond})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = fetcher.Do(req)
+
+	assert.Error(t, err)
+	assert.Equal(t, LinkErrorTimeout, linkErrorKind(err))
+}
+
+func TestHTTPFetcher_DoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.RedirectHandler("/elsewhere", http.StatusFound))
+	defer server.Close()
+
+	resp := doGet(t, NewHTTPFetcher(DefaultHTTPConfig), server.URL)
+
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "/elsewhere", resp.Header.Get("Location"))
+}
+
+func TestAnalyze_SendsUserAgentAndHeaders(t *testing.T) {
+	pageUrl := "https://example.com/"
+	fixtures := NewFixtureFetcher(map[string]Fixture{
+		pageUrl:                     {Body: `<a href="https://example.org/">link</a><a href="/about">about</a>`},
+		"https://example.org/":      {},
+		"https://example.com/about": {},
+	})
+	received := make(map[string]http.Header)
+	var mu sync.Mutex
+	d := DefaultAnalyzerService{
+		Headers: map[string]string{"Accept-Language": "en", "X-Team": "web"},
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			received[req.Method+" "+req.URL.String()] = req.Header.Clone()
+			mu.Unlock()
+			return fixtures.Do(req)
+		}),
+	}
+
+	_, err := d.Analyze(context.Background(), pageUrl,
+		WithUserAgent("ci-bot/2.0"), WithHeaders(map[string]string{"X-Team": "seo"}))
+
+	assert.NoError(t, err)
+	page := received["GET https://example.com/"]
+	assert.Equal(t, "ci-bot/2.0", page.Get("User-Agent"))
+	assert.Equal(t, "en", page.Get("Accept-Language"))
+	assert.Equal(t, "seo", page.Get("X-Team"))
+	for _, key := range []string{"HEAD https://example.org/", "HEAD https://example.com/about"} {
+		assert.Equal(t, "ci-bot/2.0", received[key].Get("User-Agent"), key)
+		assert.Empty(t, received[key].Get("X-Team"), key)
+	}
+}
+
+func TestAnalyze_DoesNotSendCredentialsToLinkedHosts(t *testing.T) {
+	pageUrl := "https://example.com/"
+	fixtures := NewFixtureFetcher(map[string]Fixture{
+		pageUrl:                        {Body: `<a href="https://tracker.example.net/">link</a>`},
+		"https://tracker.example.net/": {StatusCode: http.StatusMovedPermanently, Header: http.Header{"Location": {"https://cdn.example.net/"}}},
+		"https://cdn.example.net/":     {},
+	})
+	var mu sync.Mutex
+	var external []http.Header
+	d := DefaultAnalyzerService{
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host != "example.com" {
+				mu.Lock()
+				external = append(external, req.Header.Clone())
+				mu.Unlock()
+			}
+			return fixtures.Do(req)
+		}),
+	}
+
+	_, err := d.Analyze(context.Background(), pageUrl,
+		WithHeaders(map[string]string{"Authorization": "Bearer secret", "Cookie": "session=1", "X-Api-Key": "key"}))
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, external)
+	for _, header := range external {
+		assert.Empty(t, header.Get("Authorization"))
+		assert.Empty(t, header.Get("Cookie"))
+		assert.Empty(t, header.Get("X-Api-Key"))
+	}
+}
+
+func TestAnalyze_DefaultUserAgent(t *testing.T) {
+	pageUrl := "https://example.com/"
+	fixtures := NewFixtureFetcher(map[string]Fixture{pageUrl: {}})
+	var userAgent string
+	d := DefaultAnalyzerService{
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			userAgent = req.Header.Get("User-Agent")
+			return fixtures.Do(req)
+		}),
+	}
+
+	_, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultUserAgent, userAgent)
+}
+
+func TestAnalyze_TruncatesLargeBodies(t *testing.T) {
+	pageUrl := "https://example.com/"
+	d := newFixtureService(pageUrl, Fixture{Body: "<title>Big</title><h1>kept</h1><h2>dropped</h2>"})
+	d.MaxBodyBytes = 32
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Big", analyze.Title)
+	assert.Equal(t, 1, analyze.HeadingCounts["h1"])
+	assert.Equal(t, 0, analyze.HeadingCounts["h2"])
+	assert.Equal(t, []Issue{{
+		Code:    IssueBodyTruncated,
+		Message: "page is larger than 32 bytes; only the first 32 bytes were analyzed",
+	}}, analyze.Warnings)
+}
+
+func TestAnalyze_BlocksInternalTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<title>internal</title>"))
+	}))
+	defer server.Close()
+
+	d := DefaultAnalyzerService{}
+	_, err := d.Analyze(context.Background(), server.URL)
+
+	var blocked *netguard.BlockedError
+	assert.True(t, errors.As(err, &blocked))
+	assert.Contains(t, err.Error(), "target address 127.0.0.1 is not allowed")
+}
+
+func TestAnalyze_AllowListOverridesGuard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<title>internal</title>"))
+	}))
+	defer server.Close()
+
+	allowed, err := netguard.ParsePrefixes([]string{"127.0.0.1"})
+	assert.NoError(t, err)
+	d := DefaultAnalyzerService{
+		Fetcher: NewHTTPFetcher(HTTPConfig{Guard: netguard.New(nil, allowed)}),
+	}
+	analyze, err := d.Analyze(context.Background(), server.URL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "internal", analyze.Title)
+}
+
+func TestAnalyze_BlockedLinksAreReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	pageUrl := "https://example.com/"
+	fixtures := NewFixtureFetcher(map[string]Fixture{
+		pageUrl: {Body: `<a href="` + server.URL + `/latest/meta-data/">metadata</a>`},
+	})
+	guarded := NewHTTPFetcher(HTTPConfig{Guard: netguard.New(nil, nil)})
+	d := DefaultAnalyzerService{
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.String() == pageUrl {
+				return fixtures.Do(req)
+			}
+			return guarded.Do(req)
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithLinkDetails())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, analyze.InaccessibleLinks)
+	assert.Equal(t, LinkStatusBlocked, analyze.Links[0].Status)
+	assert.Equal(t, LinkErrorBlocked, analyze.Links[0].ErrorKind)
+}
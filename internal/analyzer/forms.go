@@ -0,0 +1,411 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"golang.org/x/net/html"
+)
+
+// formKeywords are the words that hint at the purpose of a form, looked up
+// in its attributes, field names, submit buttons and text.
+var formKeywords = map[string][]string{
+	FormLogin:         {"login", "log in", "log-in", "logon", "signin", "sign in", "sign-in"},
+	FormSignup:        {"signup", "sign up", "sign-up", "register", "create account", "create an account"},
+	FormPasswordReset: {"forgot", "reset password", "reset your password", "password reset", "reset link", "recover"},
+	FormSearch:        {"search"},
+	FormNewsletter:    {"newsletter", "subscribe"},
+	FormPayment:       {"payment", "checkout", "billing", "credit card", "card number"},
+}
+
+// formKinds is the order ties between equally likely kinds are broken in.
+var formKinds = []string{FormLogin, FormSignup, FormPasswordReset, FormPayment, FormSearch, FormNewsletter}
+
+// minFormConfidence is the score below which a form is classified as other.
+const minFormConfidence = 0.3
+
+func loginFormCheck(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	result.HasLoginForm = hasLoginForm(page.Forms())
+	return nil, nil
+}
+
+func formsCheck(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	result.Forms = page.Forms()
+	return nil, nil
+}
+
+func hasLoginForm(forms []Form) bool {
+	for _, form := range forms {
+		if form.Kind == FormLogin {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeForms inspects every form of doc on its own. base resolves the
+// form actions and may be nil.
+func analyzeForms(doc *goquery.Document, base *url.URL) []Form {
+	var forms []Form
+	index := newFormIndex(doc)
+	doc.Find("form").Each(func(i int, s *goquery.Selection) {
+		form := Form{
+			Index:   i,
+			ID:      s.AttrOr("id", ""),
+			Name:    s.AttrOr("name", ""),
+			Method:  formMethod(s.AttrOr("method", "")),
+			Action:  resolveURL(base, s.AttrOr("action", "")),
+			Enctype: formEnctype(s.AttrOr("enctype", "")),
+			Fields:  []FormField{},
+		}
+		if action, err := url.Parse(form.Action); err == nil {
+			form.SubmitsOverHTTPS = action.Scheme == "https"
+			form.CrossOrigin = base != nil && action.IsAbs() && (action.Scheme != base.Scheme || action.Host != base.Host)
+		}
+
+		var submits []string
+		for _, field := range index.controls[s.Get(0)] {
+			tag := goquery.NodeName(field)
+			fieldType := strings.ToLower(field.AttrOr("type", ""))
+			switch {
+			case tag == "button" || fieldType == "submit" || fieldType == "image":
+				if fieldType == "" || fieldType == "submit" || fieldType == "image" {
+					submits = append(submits, field.Text(), field.AttrOr("value", ""), field.AttrOr("alt", ""))
+				}
+				continue
+			case fieldType == "button" || fieldType == "reset":
+				continue
+			case tag != "input":
+				fieldType = tag
+			case fieldType == "":
+				fieldType = "text"
+			}
+			formField := FormField{
+				Name:         field.AttrOr("name", ""),
+				ID:           field.AttrOr("id", ""),
+				Type:         fieldType,
+				Required:     hasAttr(field, "required") || strings.EqualFold(field.AttrOr("aria-required", ""), "true"),
+				Autocomplete: strings.ToLower(strings.TrimSpace(field.AttrOr("autocomplete", ""))),
+			}
+			if fieldType != "hidden" {
+				formField.Label = index.label(field)
+			}
+			if fieldType == "hidden" && isCSRFToken(formField.Name) {
+				form.HasCSRFToken = true
+			}
+			form.Fields = append(form.Fields, formField)
+		}
+
+		form.Kind, form.Confidence = classifyForm(s, form.Fields, submits)
+		form.Issues = formIssues(form)
+		forms = append(forms, form)
+	})
+	return forms
+}
+
+// formIndex holds the lookups of a document the form analysis needs for
+// every control, so each is one pass over the document.
+type formIndex struct {
+	// elements and labels hold the first element with an id and the first
+	// label for an id, as browsers resolve them.
+	elements map[string]*goquery.Selection
+	labels   map[string]*goquery.Selection
+	// controls are the controls of each form, in document order.
+	controls map[*html.Node][]*goquery.Selection
+}
+
+func newFormIndex(doc *goquery.Document) formIndex {
+	index := formIndex{
+		elements: make(map[string]*goquery.Selection),
+		labels:   make(map[string]*goquery.Selection),
+		controls: make(map[*html.Node][]*goquery.Selection),
+	}
+	doc.Find("[id]").Each(func(_ int, element *goquery.Selection) {
+		if id := element.AttrOr("id", ""); index.elements[id] == nil {
+			index.elements[id] = element
+		}
+	})
+	doc.Find("label[for]").Each(func(_ int, label *goquery.Selection) {
+		if id := label.AttrOr("for", ""); index.labels[id] == nil {
+			index.labels[id] = label
+		}
+	})
+	doc.Find("input, select, textarea, button").Each(func(_ int, control *goquery.Selection) {
+		if owner := index.owner(control); owner != nil {
+			index.controls[owner] = append(index.controls[owner], control)
+		}
+	})
+	return index
+}
+
+// owner returns the form that owns control: a control naming a form in its
+// form attribute belongs to that form only, others to the form around them.
+func (index formIndex) owner(control *goquery.Selection) *html.Node {
+	if id, ok := control.Attr("form"); ok {
+		if form := index.elements[id]; form != nil && goquery.NodeName(form) == "form" {
+			return form.Get(0)
+		}
+		return nil
+	}
+	if form := control.Closest("form"); form.Length() > 0 {
+		return form.Get(0)
+	}
+	return nil
+}
+
+func hasAttr(s *goquery.Selection, name string) bool {
+	_, ok := s.Attr(name)
+	return ok
+}
+
+// label returns the accessible label text of field, or "".
+func (index formIndex) label(field *goquery.Selection) string {
+	if label := collapseSpace(field.AttrOr("aria-label", "")); label != "" {
+		return label
+	}
+	if ids := strings.Fields(field.AttrOr("aria-labelledby", "")); len(ids) > 0 {
+		var parts []string
+		for _, id := range ids {
+			if element := index.elements[id]; element != nil {
+				parts = append(parts, element.Text())
+			}
+		}
+		if label := collapseSpace(strings.Join(parts, " ")); label != "" {
+			return label
+		}
+	}
+	if id := field.AttrOr("id", ""); id != "" && index.labels[id] != nil {
+		if label := collapseSpace(index.labels[id].Text()); label != "" {
+			return label
+		}
+	}
+	if wrapping := field.Closest("label"); wrapping.Length() > 0 {
+		if label := collapseSpace(wrapping.Text()); label != "" {
+			return label
+		}
+	}
+	return collapseSpace(field.AttrOr("title", ""))
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// csrfTokenParts are parts of the names frameworks give anti-forgery
+// tokens; csrfTokenNames are full names too generic to match as parts, such
+// as Laravel's _token, which would also match captcha or payment tokens.
+var (
+	csrfTokenParts = []string{"csrf", "xsrf", "authenticity_token", "requestverificationtoken"}
+	csrfTokenNames = []string{"_token", "_wpnonce", "form_key", "form_token"}
+)
+
+func isCSRFToken(name string) bool {
+	name = strings.ToLower(name)
+	return containsAny(name, csrfTokenParts...) || slices.Contains(csrfTokenNames, name)
+}
+
+func isCardField(field FormField) bool {
+	return strings.HasPrefix(field.Autocomplete, "cc-") ||
+		containsAny(strings.ToLower(field.Name), "cardnumber", "card_number", "card-number", "cvv", "cvc", "ccnum")
+}
+
+// formIssues lists the security and usability problems of form.
+func formIssues(form Form) []Issue {
+	var issues []Issue
+	add := func(code string, format string, args ...any) {
+		issues = append(issues, Issue{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var passwords, unlabelled, missingAutocomplete []string
+	sensitive, hasFile := false, false
+	for _, field := range form.Fields {
+		name := field.Name
+		if name == "" {
+			name = field.Type
+		}
+		switch {
+		case field.Type == "password":
+			passwords = append(passwords, name)
+			sensitive = true
+			if field.Autocomplete != "current-password" && field.Autocomplete != "new-password" {
+				missingAutocomplete = append(missingAutocomplete, name)
+			}
+		case isCardField(field):
+			sensitive = true
+			if !strings.HasPrefix(field.Autocomplete, "cc-") {
+				missingAutocomplete = append(missingAutocomplete, name)
+			}
+		case field.Type == "file":
+			hasFile = true
+		}
+		if field.Type != "hidden" && field.Label == "" {
+			unlabelled = append(unlabelled, name)
+		}
+	}
+
+	if action, err := url.Parse(form.Action); err == nil && action.Scheme == "http" && (form.Method == "POST" || sensitive) {
+		add(FormIssueInsecureAction, "submits to %s without https", form.Action)
+	}
+	if form.Method == "GET" && len(passwords) > 0 {
+		add(FormIssueCredentialsInURL, "sends the password field(s) %s in the URL with GET", strings.Join(passwords, ", "))
+	}
+	if form.CrossOrigin {
+		add(FormIssueCrossOrigin, "submits to another origin: %s", form.Action)
+	}
+	if form.Method == "POST" && !form.CrossOrigin && !form.HasCSRFToken {
+		add(FormIssueMissingCSRFToken, "posts without a hidden anti-forgery token")
+	}
+	if len(missingAutocomplete) > 0 {
+		add(FormIssueMissingAutocomplete, "field(s) without a matching autocomplete token: %s", strings.Join(missingAutocomplete, ", "))
+	}
+	if len(unlabelled) > 0 {
+		add(FormIssueMissingLabel, "field(s) without a label: %s", strings.Join(unlabelled, ", "))
+	}
+	if hasFile && form.Enctype != "multipart/form-data" {
+		add(FormIssueFileWithoutMultipart, "has a file field but is not sent as multipart/form-data")
+	}
+	return issues
+}
+
+// formMethod returns the method a form submits with; anything but POST and
+// DIALOG falls back to GET, as in browsers.
+func formMethod(method string) string {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "POST" || method == "DIALOG" {
+		return method
+	}
+	return "GET"
+}
+
+// formEnctype returns the encoding a form submits with; unknown values
+// fall back to the default, as in browsers.
+func formEnctype(enctype string) string {
+	enctype = strings.ToLower(strings.TrimSpace(enctype))
+	if enctype == "multipart/form-data" || enctype == "text/plain" {
+		return enctype
+	}
+	return "application/x-www-form-urlencoded"
+}
+
+// classifyForm scores each kind of form from the fields and wording of s
+// and returns the most likely one with its score.
+func classifyForm(s *goquery.Selection, fields []FormField, submits []string) (string, float64) {
+	var passwords, emails, textInputs, searchInputs, cardFields int
+	var newPassword, currentPassword, searchName bool
+	var names []string
+	for _, field := range fields {
+		name := strings.ToLower(field.Name)
+		names = append(names, name)
+		switch field.Type {
+		case "password":
+			passwords++
+		case "email":
+			emails++
+		case "search":
+			searchInputs++
+		case "text":
+			textInputs++
+			if name == "q" || name == "s" || name == "query" || name == "search" {
+				searchName = true
+			}
+		}
+		switch {
+		case field.Autocomplete == "new-password":
+			newPassword = true
+		case field.Autocomplete == "current-password", field.Autocomplete == "username":
+			currentPassword = true
+		case field.Autocomplete == "email" && field.Type != "email":
+			emails++
+		}
+		if isCardField(field) {
+			cardFields++
+		}
+	}
+
+	words := strings.ToLower(strings.Join(append(append([]string{
+		s.AttrOr("id", ""), s.AttrOr("name", ""), s.AttrOr("class", ""),
+		s.AttrOr("action", ""), s.AttrOr("aria-label", ""), s.Text(),
+	}, submits...), names...), " "))
+	words = strings.Join(strings.Fields(words), " ")
+	mentions := func(kind string) bool {
+		return containsAny(words, formKeywords[kind]...)
+	}
+
+	scores := make(map[string]float64, len(formKinds))
+	if passwords == 1 && !newPassword {
+		scores[FormLogin] += 0.6
+	}
+	if currentPassword {
+		scores[FormLogin] += 0.2
+	}
+	if mentions(FormLogin) {
+		scores[FormLogin] += 0.4
+	}
+
+	if passwords >= 2 || newPassword {
+		scores[FormSignup] += 0.5
+	}
+	if mentions(FormSignup) {
+		scores[FormSignup] += 0.4
+	}
+	if passwords > 0 && emails > 0 {
+		scores[FormSignup] += 0.1
+	}
+
+	if mentions(FormPasswordReset) {
+		scores[FormPasswordReset] += 0.5
+		if passwords == 0 && emails+textInputs == 1 {
+			scores[FormPasswordReset] += 0.3
+		}
+	}
+
+	if cardFields > 0 {
+		scores[FormPayment] += 0.6
+	}
+	if mentions(FormPayment) {
+		scores[FormPayment] += 0.3
+	}
+
+	if searchInputs > 0 || strings.EqualFold(s.AttrOr("role", ""), "search") {
+		scores[FormSearch] += 0.6
+	}
+	if searchName {
+		scores[FormSearch] += 0.3
+	}
+	if mentions(FormSearch) {
+		scores[FormSearch] += 0.3
+	}
+
+	if mentions(FormNewsletter) {
+		scores[FormNewsletter] += 0.5
+	}
+	if passwords == 0 && emails == 1 && textInputs <= 1 {
+		scores[FormNewsletter] += 0.3
+	}
+
+	kind, best := FormOther, 0.0
+	for _, candidate := range formKinds {
+		if scores[candidate] > best {
+			kind, best = candidate, scores[candidate]
+		}
+	}
+	if best < minFormConfidence {
+		return FormOther, 0
+	}
+	return kind, math.Round(math.Min(best, 1)*100) / 100
+}
+
+func containsAny(s string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
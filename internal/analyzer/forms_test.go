@@ -0,0 +1,327 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyForms(t *testing.T) {
+	tests := []struct {
+		name       string
+		html       string
+		kind       string
+		confidence float64
+	}{
+		{
+			name: "Login",
+			html: `<form action="/session" method="post">
+				<input name="email" type="email" autocomplete="username">
+				<input name="password" type="password" autocomplete="current-password">
+				<button>Sign in</button>
+				<a href="/forgot">Forgot your password?</a>
+			</form>`,
+			kind:       FormLogin,
+			confidence: 1,
+		},
+		{
+			name: "Signup",
+			html: `<form action="/users" method="post">
+				<input name="email" type="email">
+				<input name="password" type="password" autocomplete="new-password">
+				<input name="password_confirmation" type="password" autocomplete="new-password">
+				<input type="submit" value="Create account">
+			</form>`,
+			kind:       FormSignup,
+			confidence: 1,
+		},
+		{
+			name: "Password reset",
+			html: `<form action="/password/reset" method="post">
+				<input name="email" type="email">
+				<button>Send reset link</button>
+			</form>`,
+			kind:       FormPasswordReset,
+			confidence: 0.8,
+		},
+		{
+			name: "Search",
+			html: `<form role="search" action="/search">
+				<input name="q" type="search">
+				<button>Go</button>
+			</form>`,
+			kind:       FormSearch,
+			confidence: 0.9,
+		},
+		{
+			name: "Newsletter",
+			html: `<form action="https://lists.example.org/add" method="post">
+				<input name="email" type="email" placeholder="you@example.com">
+				<button>Subscribe</button>
+			</form>`,
+			kind:       FormNewsletter,
+			confidence: 0.8,
+		},
+		{
+			name: "Payment",
+			html: `<form action="/checkout" method="post">
+				<input name="cardnumber" autocomplete="cc-number">
+				<input name="exp" autocomplete="cc-exp">
+				<input name="cvc" autocomplete="cc-csc">
+				<button>Pay now</button>
+			</form>`,
+			kind:       FormPayment,
+			confidence: 0.9,
+		},
+		{
+			name: "Other",
+			html: `<form action="/comments" method="post">
+				<textarea name="body"></textarea>
+				<button>Post comment</button>
+			</form>`,
+			kind:       FormOther,
+			confidence: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			assert.NoError(t, err)
+
+			forms := analyzeForms(doc, nil)
+			assert.Len(t, forms, 1)
+			assert.Equal(t, tt.kind, forms[0].Kind)
+			assert.Equal(t, tt.confidence, forms[0].Confidence)
+		})
+	}
+}
+
+func TestAnalyzeForms_ShouldDescribeEachForm(t *testing.T) {
+	html := `<html><body>
+		<nav><a href="/login">Sign in</a></nav>
+		<form id="search" action="/search"><input name="q" aria-label="Search the site"></form>
+		<form id="login" method="post" action="http://example.com/session">
+			<label for="user">Email</label>
+			<input id="user" name="user" required>
+			<input name="pass" type="password">
+			<input type="hidden" name="token" value="x">
+			<input type="submit" value="Log in">
+			<button type="button">Show password</button>
+		</form>
+		<form id="upload" method="post" enctype="TEXT/PLAIN" action="/upload">
+			<input type="hidden" name="csrf_token" value="x">
+			<label>Attachment <input type="file" name="file" aria-required="true"></label>
+		</form>
+		<textarea form="upload" name="note" title="Note"></textarea>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	assert.NoError(t, err)
+	base, _ := url.Parse("https://example.com/home")
+
+	forms := analyzeForms(doc, base)
+
+	assert.Equal(t, []Form{
+		{
+			Index:            0,
+			ID:               "search",
+			Method:           "GET",
+			Action:           "https://example.com/search",
+			Enctype:          "application/x-www-form-urlencoded",
+			Kind:             FormSearch,
+			Confidence:       0.6,
+			Fields:           []FormField{{Name: "q", Type: "text", Label: "Search the site"}},
+			SubmitsOverHTTPS: true,
+		},
+		{
+			Index:      1,
+			ID:         "login",
+			Method:     "POST",
+			Action:     "http://example.com/session",
+			Enctype:    "application/x-www-form-urlencoded",
+			Kind:       FormLogin,
+			Confidence: 1,
+			Fields: []FormField{
+				{Name: "user", ID: "user", Type: "text", Label: "Email", Required: true},
+				{Name: "pass", Type: "password"},
+				{Name: "token", Type: "hidden"},
+			},
+			SubmitsOverHTTPS: false,
+			CrossOrigin:      true,
+			Issues: []Issue{
+				{Code: FormIssueInsecureAction, Message: "submits to http://example.com/session without https"},
+				{Code: FormIssueCrossOrigin, Message: "submits to another origin: http://example.com/session"},
+				{Code: FormIssueMissingAutocomplete, Message: "field(s) without a matching autocomplete token: pass"},
+				{Code: FormIssueMissingLabel, Message: "field(s) without a label: pass"},
+			},
+		},
+		{
+			Index:      2,
+			ID:         "upload",
+			Method:     "POST",
+			Action:     "https://example.com/upload",
+			Enctype:    "text/plain",
+			Kind:       FormOther,
+			Confidence: 0,
+			Fields: []FormField{
+				{Name: "csrf_token", Type: "hidden"},
+				{Name: "file", Type: "file", Label: "Attachment", Required: true},
+				{Name: "note", Type: "textarea", Label: "Note"},
+			},
+			SubmitsOverHTTPS: true,
+			HasCSRFToken:     true,
+			Issues: []Issue{
+				{Code: FormIssueFileWithoutMultipart, Message: "has a file field but is not sent as multipart/form-data"},
+			},
+		},
+	}, forms)
+}
+
+func TestAnalyzeForms_ResolvesLabelsAndOwners(t *testing.T) {
+	html := `<html><body>
+		<span id="first">Given</span><span id="last">name</span>
+		<div id="contact"></div>
+		<form id="profile">
+			<input name="name" aria-labelledby="first last">
+			<input id="mail" name="mail" type="email">
+			<input name="moved" form="contact">
+		</form>
+		<label for="mail">Email address</label>
+		<label for="mail">Ignored</label>
+		<input name="phone" form="profile" title="Phone">
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	assert.NoError(t, err)
+
+	forms := analyzeForms(doc, nil)
+
+	assert.Len(t, forms, 1)
+	assert.Equal(t, []FormField{
+		{Name: "name", Type: "text", Label: "Given name"},
+		{Name: "mail", ID: "mail", Type: "email", Label: "Email address"},
+		{Name: "phone", Type: "text", Label: "Phone"},
+	}, forms[0].Fields)
+}
+
+func TestPageForms_AreAnalyzedOnce(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<form><input name="user"><input name="pass" type="password"></form>`))
+	assert.NoError(t, err)
+	page := &Page{Document: doc}
+	var result PageAnalysisResponse
+
+	_, err = loginFormCheck(context.Background(), page, &result)
+	assert.NoError(t, err)
+	_, err = formsCheck(context.Background(), page, &result)
+	assert.NoError(t, err)
+
+	assert.True(t, result.HasLoginForm)
+	assert.Len(t, result.Forms, 1)
+	assert.Same(t, &page.Forms()[0], &result.Forms[0])
+}
+
+func TestIsCSRFToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "csrf_token", expected: true},
+		{name: "csrfmiddlewaretoken", expected: true},
+		{name: "_xsrf", expected: true},
+		{name: "authenticity_token", expected: true},
+		{name: "__RequestVerificationToken", expected: true},
+		{name: "_token", expected: true},
+		{name: "_wpnonce", expected: true},
+		{name: "form_key", expected: true},
+		{name: "token"},
+		{name: "nonce"},
+		{name: "captcha_token"},
+		{name: "g-recaptcha-response"},
+		{name: "payment_token"},
+		{name: "stripeToken"},
+		{name: "script_nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isCSRFToken(tt.name))
+		})
+	}
+}
+
+func TestAnalyzeForms_CaptchaAndPaymentTokensAreNotCSRFTokens(t *testing.T) {
+	html := `<form method="post" action="/checkout">
+		<input type="hidden" name="captcha_token" value="x">
+		<input type="hidden" name="payment_token" value="y">
+		<button>Pay</button>
+	</form>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	assert.NoError(t, err)
+	base, _ := url.Parse("https://example.com/cart")
+
+	forms := analyzeForms(doc, base)
+
+	assert.False(t, forms[0].HasCSRFToken)
+	assert.Equal(t, []Issue{{Code: FormIssueMissingCSRFToken, Message: "posts without a hidden anti-forgery token"}}, forms[0].Issues)
+}
+
+func TestFormIssues(t *testing.T) {
+	tests := []struct {
+		name     string
+		form     Form
+		expected []string
+	}{
+		{
+			name: "Password sent with GET",
+			form: Form{Method: "GET", Action: "https://example.com/login", Fields: []FormField{
+				{Name: "pass", Type: "password", Label: "Password", Autocomplete: "current-password"},
+			}},
+			expected: []string{FormIssueCredentialsInURL},
+		},
+		{
+			name: "Same-origin post without token",
+			form: Form{Method: "POST", Action: "https://example.com/comments", Fields: []FormField{
+				{Name: "body", Type: "textarea", Label: "Comment"},
+			}},
+			expected: []string{FormIssueMissingCSRFToken},
+		},
+		{
+			name: "Card field without cc autocomplete",
+			form: Form{Method: "POST", Action: "https://pay.example.org/", CrossOrigin: true, Fields: []FormField{
+				{Name: "cardnumber", Type: "text", Label: "Card number", Autocomplete: "off"},
+			}},
+			expected: []string{FormIssueCrossOrigin, FormIssueMissingAutocomplete},
+		},
+		{
+			name: "Plain http search is fine",
+			form: Form{Method: "GET", Action: "http://example.com/search", Fields: []FormField{
+				{Name: "q", Type: "search", Label: "Search"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []string
+			for _, issue := range formIssues(tt.form) {
+				codes = append(codes, issue.Code)
+			}
+			assert.Equal(t, tt.expected, codes)
+		})
+	}
+}
+
+func TestDetectLoginForm_IgnoresSignInLinksOutsideForms(t *testing.T) {
+	html := `<html><body>
+		<nav><a href="/login">Sign in</a> or <a href="/signup">sign up</a></nav>
+		<form action="/search"><input type="search" name="q"></form>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	assert.NoError(t, err)
+
+	assert.False(t, hasLoginForm(analyzeForms(doc, nil)))
+}
@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+func headingsCheck(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	getHeadingCount(page.Document, *result)
+	headings := findHeadings(page.Document)
+	result.Outline = buildOutline(headings)
+	result.HeadingIssues = headingIssues(headings)
+	return nil, nil
+}
+
+func getHeadingCount(doc *goquery.Document, result PageAnalysisResponse) {
+	for i := 1; i <= 6; i++ {
+		selector := fmt.Sprintf("h%d", i)
+		count := doc.Find(selector).Length()
+		if count > 0 {
+			result.HeadingCounts[selector] = count
+		}
+	}
+}
+
+// heading is an h1-h6 element in document order. hiddenBy names what hides
+// it, if anything.
+type heading struct {
+	level    int
+	text     string
+	hiddenBy string
+}
+
+// label names the heading at index i in messages, e.g. `heading 2 (h2 "Usage")`.
+func (h heading) label(i int) string {
+	if h.text == "" {
+		return fmt.Sprintf("heading %d (h%d)", i+1, h.level)
+	}
+	return fmt.Sprintf("heading %d (h%d %q)", i+1, h.level, h.text)
+}
+
+func findHeadings(doc *goquery.Document) []heading {
+	var headings []heading
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+		headings = append(headings, heading{
+			level:    int(goquery.NodeName(s)[1] - '0'),
+			text:     headingText(s),
+			hiddenBy: hiddenBy(s),
+		})
+	})
+	return headings
+}
+
+// headingText is the text a heading is announced with: its aria-label, or
+// its text including the alt text of images.
+func headingText(s *goquery.Selection) string {
+	if label := collapseSpace(s.AttrOr("aria-label", "")); label != "" {
+		return label
+	}
+	parts := []string{s.Text()}
+	s.Find("img[alt]").Each(func(_ int, img *goquery.Selection) {
+		parts = append(parts, img.AttrOr("alt", ""))
+	})
+	return collapseSpace(strings.Join(parts, " "))
+}
+
+// hiddenBy reports what hides s or one of its ancestors: aria-hidden, the
+// hidden attribute or an inline display:none style.
+func hiddenBy(s *goquery.Selection) string {
+	for node := s; node.Length() > 0 && goquery.NodeName(node) != "html"; node = node.Parent() {
+		if strings.EqualFold(strings.TrimSpace(node.AttrOr("aria-hidden", "")), "true") {
+			return "aria-hidden"
+		}
+		if hasAttr(node, "hidden") {
+			return "the hidden attribute"
+		}
+		style := strings.Join(strings.Fields(strings.ToLower(node.AttrOr("style", ""))), "")
+		for _, declaration := range strings.Split(style, ";") {
+			if strings.HasPrefix(declaration, "display:none") {
+				return "display:none"
+			}
+		}
+	}
+	return ""
+}
+
+// buildOutline nests each heading under the closest preceding heading of a
+// higher rank.
+func buildOutline(headings []heading) []HeadingNode {
+	var build func(i int) (HeadingNode, int)
+	build = func(i int) (HeadingNode, int) {
+		node := HeadingNode{Level: headings[i].level, Text: headings[i].text, Hidden: headings[i].hiddenBy != ""}
+		next := i + 1
+		for next < len(headings) && headings[next].level > node.Level {
+			var child HeadingNode
+			child, next = build(next)
+			node.Children = append(node.Children, child)
+		}
+		return node, next
+	}
+
+	var outline []HeadingNode
+	for i := 0; i < len(headings); {
+		var node HeadingNode
+		node, i = build(i)
+		outline = append(outline, node)
+	}
+	return outline
+}
+
+// headingIssues checks the hierarchy of the headings. Hidden headings do not
+// count towards the h1s of the page.
+func headingIssues(headings []heading) []Issue {
+	var issues []Issue
+	add := func(code string, format string, args ...any) {
+		issues = append(issues, Issue{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	h1Count, hiddenH1Count := 0, 0
+	for _, h := range headings {
+		switch {
+		case h.level != 1:
+		case h.hiddenBy != "":
+			hiddenH1Count++
+		default:
+			h1Count++
+		}
+	}
+	switch {
+	case h1Count == 0 && hiddenH1Count > 0:
+		add(HeadingIssueMissingH1, "the page has no visible h1 heading")
+	case h1Count == 0:
+		add(HeadingIssueMissingH1, "the page has no h1 heading")
+	case h1Count > 1:
+		add(HeadingIssueMultipleH1, "the page has %d h1 headings", h1Count)
+	}
+
+	previous := 0
+	for i, h := range headings {
+		if h.text == "" {
+			add(HeadingIssueEmpty, "%s is empty", h.label(i))
+		}
+		if h.hiddenBy != "" {
+			add(HeadingIssueHidden, "%s is hidden by %s", h.label(i), h.hiddenBy)
+			continue
+		}
+		if previous > 0 && h.level > previous+1 {
+			add(HeadingIssueSkippedLevel, "%s follows an h%d, skipping h%d", h.label(i), previous, previous+1)
+		}
+		previous = h.level
+	}
+	return issues
+}
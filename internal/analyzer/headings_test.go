@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func runHeadingsCheck(t *testing.T, html string) PageAnalysisResponse {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	assert.NoError(t, err)
+	result := PageAnalysisResponse{HeadingCounts: make(map[string]int)}
+	_, err = headingsCheck(context.Background(), &Page{Document: doc}, &result)
+	assert.NoError(t, err)
+	return result
+}
+
+func TestHeadingsCheck_ShouldBuildOutline(t *testing.T) {
+	result := runHeadingsCheck(t, `<html><body>
+		<h1>Guide</h1>
+		<h2>Install</h2>
+		<h3>Linux</h3>
+		<h3>macOS</h3>
+		<h2>Use <img src="x.png" alt="quickly"></h2>
+		<h4>Flags</h4>
+		<h2 aria-label="FAQ">?</h2>
+		<h0>Not a heading</h0>
+	</body></html>`)
+
+	assert.Equal(t, map[string]int{"h1": 1, "h2": 3, "h3": 2, "h4": 1}, result.HeadingCounts)
+	assert.Equal(t, []HeadingNode{{
+		Level: 1,
+		Text:  "Guide",
+		Children: []HeadingNode{
+			{Level: 2, Text: "Install", Children: []HeadingNode{
+				{Level: 3, Text: "Linux"},
+				{Level: 3, Text: "macOS"},
+			}},
+			{Level: 2, Text: "Use quickly", Children: []HeadingNode{
+				{Level: 4, Text: "Flags"},
+			}},
+			{Level: 2, Text: "FAQ"},
+		},
+	}}, result.Outline)
+	assert.Equal(t, []Issue{
+		{Code: HeadingIssueSkippedLevel, Message: `heading 6 (h4 "Flags") follows an h2, skipping h3`},
+	}, result.HeadingIssues)
+}
+
+func TestHeadingsCheck_ShouldReportHierarchyIssues(t *testing.T) {
+	result := runHeadingsCheck(t, `<html><body>
+		<h2>Intro</h2>
+		<h1>First</h1>
+		<h1>Second</h1>
+		<h3>   </h3>
+		<nav aria-hidden="true"><h4>Menu</h4></nav>
+		<div style="color: red; DISPLAY : none"><h2>Popup</h2></div>
+		<h5 hidden>Secret</h5>
+		<h2>Outro</h2>
+	</body></html>`)
+
+	assert.Equal(t, []HeadingNode{
+		{Level: 2, Text: "Intro"},
+		{Level: 1, Text: "First"},
+		{Level: 1, Text: "Second", Children: []HeadingNode{
+			{Level: 3, Text: "", Children: []HeadingNode{
+				{Level: 4, Text: "Menu", Hidden: true},
+			}},
+			{Level: 2, Text: "Popup", Hidden: true, Children: []HeadingNode{
+				{Level: 5, Text: "Secret", Hidden: true},
+			}},
+			{Level: 2, Text: "Outro"},
+		}},
+	}, result.Outline)
+	assert.Equal(t, []Issue{
+		{Code: HeadingIssueMultipleH1, Message: "the page has 2 h1 headings"},
+		{Code: HeadingIssueEmpty, Message: "heading 4 (h3) is empty"},
+		{Code: HeadingIssueSkippedLevel, Message: "heading 4 (h3) follows an h1, skipping h2"},
+		{Code: HeadingIssueHidden, Message: `heading 5 (h4 "Menu") is hidden by aria-hidden`},
+		{Code: HeadingIssueHidden, Message: `heading 6 (h2 "Popup") is hidden by display:none`},
+		{Code: HeadingIssueHidden, Message: `heading 7 (h5 "Secret") is hidden by the hidden attribute`},
+	}, result.HeadingIssues)
+}
+
+func TestHeadingsCheck_ShouldReportMissingH1(t *testing.T) {
+	result := runHeadingsCheck(t, `<html><body><p>No headings</p></body></html>`)
+
+	assert.Empty(t, result.HeadingCounts)
+	assert.Nil(t, result.Outline)
+	assert.Equal(t, []Issue{{Code: HeadingIssueMissingH1, Message: "the page has no h1 heading"}}, result.HeadingIssues)
+}
+
+func TestHeadingsCheck_HiddenH1DoesNotCount(t *testing.T) {
+	result := runHeadingsCheck(t, `<html><body><h1 aria-hidden="true">Logo</h1><h2>Intro</h2></body></html>`)
+
+	assert.Equal(t, map[string]int{"h1": 1, "h2": 1}, result.HeadingCounts)
+	assert.Equal(t, []Issue{
+		{Code: HeadingIssueMissingH1, Message: "the page has no visible h1 heading"},
+		{Code: HeadingIssueHidden, Message: `heading 1 (h1 "Logo") is hidden by aria-hidden`},
+	}, result.HeadingIssues)
+
+	result = runHeadingsCheck(t, `<html><body><h1>Title</h1><div hidden><h1>Dialog</h1></div></body></html>`)
+
+	assert.Equal(t, []Issue{
+		{Code: HeadingIssueHidden, Message: `heading 2 (h1 "Dialog") is hidden by the hidden attribute`},
+	}, result.HeadingIssues)
+}
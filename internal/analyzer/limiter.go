@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+var DefaultLinkCheckLimits = model.LinkCheckLimits{
+	Workers: 16,
+	PerHost: 4,
+}
+
+// resolveLinkCheckLimits layers the service limits over the defaults and
+// the request limits over both. A request may only tighten the service
+// limits: fewer workers, fewer connections per host or a longer spacing.
+func resolveLinkCheckLimits(service model.LinkCheckLimits, request model.LinkCheckLimits) model.LinkCheckLimits {
+	limits := DefaultLinkCheckLimits
+	if service.Workers > 0 {
+		limits.Workers = service.Workers
+	}
+	if service.PerHost > 0 {
+		limits.PerHost = service.PerHost
+	}
+	if service.PerHostIntervalMs > 0 {
+		limits.PerHostIntervalMs = service.PerHostIntervalMs
+	}
+
+	if request.Workers > 0 && request.Workers < limits.Workers {
+		limits.Workers = request.Workers
+	}
+	if request.PerHost > 0 && request.PerHost < limits.PerHost {
+		limits.PerHost = request.PerHost
+	}
+	if request.PerHostIntervalMs > limits.PerHostIntervalMs {
+		limits.PerHostIntervalMs = request.PerHostIntervalMs
+	}
+	if limits.PerHost > limits.Workers {
+		limits.PerHost = limits.Workers
+	}
+	return limits
+}
+
+// linkLimiter hands out link-check slots per host: at most PerHost checks
+// against a single host and, when an interval is set, requests to the same
+// host started no closer together than that. The number of workers bounds
+// the checks in flight overall.
+type linkLimiter struct {
+	perHost  int
+	interval time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+type hostLimiter struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newLinkLimiter(limits model.LinkCheckLimits) *linkLimiter {
+	return &linkLimiter{
+		perHost:  limits.PerHost,
+		interval: time.Duration(limits.PerHostIntervalMs) * time.Millisecond,
+		hosts:    make(map[string]*hostLimiter),
+	}
+}
+
+func (l *linkLimiter) host(name string) *hostLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.hosts[name]
+	if !ok {
+		h = &hostLimiter{slots: make(chan struct{}, l.perHost)}
+		l.hosts[name] = h
+	}
+	return h
+}
+
+// acquire blocks until a check against host may start. The returned release
+// must be called once the check is done.
+func (l *linkLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	h := l.host(host)
+
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if err := h.wait(ctx, l.interval); err != nil {
+		<-h.slots
+		return nil, err
+	}
+
+	return func() {
+		<-h.slots
+	}, nil
+}
+
+func (h *hostLimiter) wait(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	start := h.next
+	if start.Before(now) {
+		start = now
+	}
+	h.next = start.Add(interval)
+	h.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLinkCheckLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  model.LinkCheckLimits
+		request  model.LinkCheckLimits
+		expected model.LinkCheckLimits
+	}{
+		{
+			name:     "Defaults",
+			expected: DefaultLinkCheckLimits,
+		},
+		{
+			name:     "Service overrides defaults",
+			service:  model.LinkCheckLimits{Workers: 64, PerHost: 8, PerHostIntervalMs: 10},
+			expected: model.LinkCheckLimits{Workers: 64, PerHost: 8, PerHostIntervalMs: 10},
+		},
+		{
+			name:     "Request can tighten the limits",
+			service:  model.LinkCheckLimits{Workers: 64, PerHost: 8, PerHostIntervalMs: 10},
+			request:  model.LinkCheckLimits{Workers: 4, PerHost: 1, PerHostIntervalMs: 250},
+			expected: model.LinkCheckLimits{Workers: 4, PerHost: 1, PerHostIntervalMs: 250},
+		},
+		{
+			name:     "Request cannot loosen the limits",
+			service:  model.LinkCheckLimits{Workers: 8, PerHost: 2, PerHostIntervalMs: 100},
+			request:  model.LinkCheckLimits{Workers: 1000, PerHost: 100, PerHostIntervalMs: 1},
+			expected: model.LinkCheckLimits{Workers: 8, PerHost: 2, PerHostIntervalMs: 100},
+		},
+		{
+			name:     "Per host never exceeds workers",
+			request:  model.LinkCheckLimits{Workers: 2},
+			expected: model.LinkCheckLimits{Workers: 2, PerHost: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolveLinkCheckLimits(tt.service, tt.request))
+		})
+	}
+}
+
+func TestLinksAnalyzer_RespectsWorkerAndPerHostLimits(t *testing.T) {
+	var links strings.Builder
+	for i := 0; i < 30; i++ {
+		links.WriteString(fmt.Sprintf(`<a href="https://host%d.example.org/page%d">link</a>`, i%3, i))
+	}
+	pageUrl := "https://example.com/"
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	perHost, maxPerHost := map[string]int{}, 0
+
+	fixtures := NewFixtureFetcher(map[string]Fixture{pageUrl: {Body: links.String()}})
+	d := DefaultAnalyzerService{
+		LinkCheckLimits: model.LinkCheckLimits{Workers: 5, PerHost: 2},
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet {
+				return fixtures.Do(req)
+			}
+			mu.Lock()
+			inFlight++
+			perHost[req.URL.Host]++
+			maxInFlight = max(maxInFlight, inFlight)
+			maxPerHost = max(maxPerHost, perHost[req.URL.Host])
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			perHost[req.URL.Host]--
+			mu.Unlock()
+			return newResponse(req, http.StatusOK, nil, nil), nil
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 30, analyze.ExternalLinks)
+	assert.Equal(t, 0, analyze.InaccessibleLinks)
+	assert.LessOrEqual(t, maxInFlight, 5)
+	assert.LessOrEqual(t, maxPerHost, 2)
+	assert.Equal(t, model.LinkCheckLimits{Workers: 5, PerHost: 2}, analyze.LinkCheckLimits)
+}
+
+func TestLinksAnalyzer_RunsAFixedNumberOfWorkers(t *testing.T) {
+	var links strings.Builder
+	for i := 0; i < 200; i++ {
+		links.WriteString(fmt.Sprintf(`<a href="https://host%d.example.org/">link</a>`, i))
+	}
+	pageUrl := "https://example.com/"
+
+	baseline := runtime.NumGoroutine()
+	var mu sync.Mutex
+	maxGoroutines := 0
+
+	fixtures := NewFixtureFetcher(map[string]Fixture{pageUrl: {Body: links.String()}})
+	d := DefaultAnalyzerService{
+		LinkCheckLimits: model.LinkCheckLimits{Workers: 2},
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.String() == pageUrl {
+				return fixtures.Do(req)
+			}
+			mu.Lock()
+			maxGoroutines = max(maxGoroutines, runtime.NumGoroutine())
+			mu.Unlock()
+			return newResponse(req, http.StatusOK, nil, nil), nil
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, analyze.ExternalLinks)
+	assert.Less(t, maxGoroutines-baseline, 20)
+}
+
+func TestLinkLimiter_SpacesRequestsToTheSameHost(t *testing.T) {
+	limiter := newLinkLimiter(model.LinkCheckLimits{Workers: 4, PerHost: 4, PerHostIntervalMs: 20})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := limiter.acquire(context.Background(), "example.org")
+		assert.NoError(t, err)
+		release()
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestLinkLimiter_StopsWaitingWhenContextEnds(t *testing.T) {
+	limiter := newLinkLimiter(model.LinkCheckLimits{Workers: 1, PerHost: 1})
+	release, err := limiter.acquire(context.Background(), "example.org")
+	assert.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.acquire(ctx, "example.org")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,220 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
+)
+
+// LinkCheckRules decide how a link check talks to the target server and how
+// the answer is classified.
+type LinkCheckRules struct {
+	// FallbackStatusCodes are HEAD answers that are retried as a small
+	// ranged GET, for servers that reject HEAD but serve the page fine.
+	FallbackStatusCodes []int
+	// RestrictedStatusCodes mark a link as restricted rather than broken:
+	// the target exists but this client may not see it.
+	RestrictedStatusCodes []int
+	// RetryStatusCodes are retried up to MaxRetries times, waiting for the
+	// Retry-After header (capped at MaxRetryAfter) or an exponential
+	// backoff starting at RetryBackoff.
+	RetryStatusCodes []int
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	MaxRetryAfter    time.Duration
+}
+
+var DefaultLinkCheckRules = LinkCheckRules{
+	FallbackStatusCodes:   []int{http.StatusForbidden, http.StatusMethodNotAllowed, http.StatusNotImplemented},
+	RestrictedStatusCodes: []int{http.StatusUnauthorized, http.StatusForbidden},
+	RetryStatusCodes:      []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+	MaxRetries:            2,
+	RetryBackoff:          500 * time.Millisecond,
+	MaxRetryAfter:         10 * time.Second,
+}
+
+// maxFallbackBody is how much of a fallback GET body is drained so the
+// connection can be reused; the rest is dropped with the connection.
+const maxFallbackBody = 4 << 10
+
+type linkOutcome struct {
+	Status     string
+	Checked    bool
+	Method     string
+	Attempts   int
+	StatusCode int
+	LatencyMs  int64
+	ErrorKind  string
+	Redirects  *RedirectChain
+}
+
+func (o linkOutcome) apply(link *LinkDetail) {
+	link.Status = o.Status
+	link.Checked = o.Checked
+	link.Method = o.Method
+	link.Attempts = o.Attempts
+	link.StatusCode = o.StatusCode
+	link.LatencyMs = o.LatencyMs
+	link.ErrorKind = o.ErrorKind
+	link.Redirects = o.Redirects
+}
+
+// checkLink requests link and reports the outcome. A check cut short by the
+// analysis context leaves the link unchecked rather than broken.
+func checkLink(ctx context.Context, fetcher Fetcher, opts Options, link string) linkOutcome {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		log.Printf("[DEBUG] Link not accessible: %s, err: %v", link, err)
+		return linkOutcome{Status: LinkStatusInvalid, Checked: true, ErrorKind: LinkErrorInvalidURL}
+	}
+	opts.setLinkHeaders(req)
+	rules := opts.linkCheckRules()
+	maxRedirects := opts.maxRedirects
+
+	start := time.Now()
+	outcome := linkOutcome{Method: http.MethodHead}
+	statusCode, redirects, attempts, err := requestWithRetry(ctx, fetcher, rules, maxRedirects, req)
+	outcome.Attempts = attempts
+
+	if err == nil && slices.Contains(rules.FallbackStatusCodes, statusCode) {
+		log.Printf("[DEBUG] HEAD rejected with %d for %s, falling back to GET", statusCode, link)
+		get := req.Clone(ctx)
+		get.Method = http.MethodGet
+		get.Header.Set("Range", "bytes=0-0")
+		outcome.Method = http.MethodGet
+		statusCode, redirects, attempts, err = requestWithRetry(ctx, fetcher, rules, maxRedirects, get)
+		outcome.Attempts += attempts
+	}
+	outcome.LatencyMs = time.Since(start).Milliseconds()
+	outcome.Redirects = chainOrNil(redirects)
+
+	if ctx.Err() != nil {
+		return linkOutcome{Status: LinkStatusUnchecked}
+	}
+	outcome.Checked = true
+
+	if err != nil {
+		log.Printf("[DEBUG] Link not accessible: %s, err: %v", link, err)
+		outcome.ErrorKind = linkErrorKind(err)
+		switch outcome.ErrorKind {
+		case LinkErrorTimeout:
+			outcome.Status = LinkStatusTimeout
+		case LinkErrorRedirect:
+			outcome.Status = LinkStatusBroken
+		case LinkErrorBlocked:
+			outcome.Status = LinkStatusBlocked
+		default:
+			outcome.Status = LinkStatusError
+		}
+		return outcome
+	}
+
+	outcome.StatusCode = statusCode
+	outcome.Status, outcome.ErrorKind = classifyStatus(rules, outcome.Method, statusCode)
+	if outcome.ErrorKind != "" {
+		log.Printf("[DEBUG] Link not accessible: %s, status: %d", link, statusCode)
+	}
+	return outcome
+}
+
+// requestWithRetry sends req, following redirects and repeating it while the
+// server answers with one of the retry status codes. It returns the last
+// status code, its redirect chain and the number of requests made.
+func requestWithRetry(ctx context.Context, fetcher Fetcher, rules LinkCheckRules, maxRedirects int, req *http.Request) (int, *RedirectChain, int, error) {
+	for attempt := 0; ; attempt++ {
+		resp, redirects, err := followRedirects(fetcher, req.Clone(ctx), maxRedirects)
+		if err != nil {
+			return 0, redirects, attempt + 1, err
+		}
+		_, _ = io.CopyN(io.Discard, resp.Body, maxFallbackBody)
+		resp.Body.Close()
+
+		if attempt >= rules.MaxRetries || !slices.Contains(rules.RetryStatusCodes, resp.StatusCode) {
+			return resp.StatusCode, redirects, attempt + 1, nil
+		}
+
+		delay := retryDelay(resp.Header.Get("Retry-After"), attempt, rules)
+		log.Printf("[DEBUG] %s %s answered %d, retrying in %v", req.Method, req.URL, resp.StatusCode, delay)
+		if err := sleep(ctx, delay); err != nil {
+			return resp.StatusCode, redirects, attempt + 1, err
+		}
+	}
+}
+
+func retryDelay(retryAfter string, attempt int, rules LinkCheckRules) time.Duration {
+	delay := rules.RetryBackoff << attempt
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(retryAfter); err == nil {
+		delay = time.Until(at)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if rules.MaxRetryAfter > 0 && delay > rules.MaxRetryAfter {
+		delay = rules.MaxRetryAfter
+	}
+	return delay
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func classifyStatus(rules LinkCheckRules, method string, statusCode int) (string, string) {
+	switch {
+	case statusCode < 400:
+		return LinkStatusOK, ""
+	case method == http.MethodGet && statusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The resource exists; it just has no first byte to return.
+		return LinkStatusOK, ""
+	case slices.Contains(rules.RestrictedStatusCodes, statusCode):
+		return LinkStatusRestricted, ""
+	case statusCode == http.StatusTooManyRequests:
+		return LinkStatusRateLimited, LinkErrorHTTPStatus
+	default:
+		return LinkStatusBroken, LinkErrorHTTPStatus
+	}
+}
+
+func linkErrorKind(err error) string {
+	if errors.Is(err, ErrRedirectLoop) || errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrRedirectScheme) {
+		return LinkErrorRedirect
+	}
+	var blocked *netguard.BlockedError
+	if errors.As(err, &blocked) {
+		return LinkErrorBlocked
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &timeout) && timeout.Timeout()) {
+		return LinkErrorTimeout
+	}
+	return LinkErrorNetwork
+}
+
+// IsInaccessible reports whether a link counts towards the inaccessible
+// totals. Restricted and rate-limited links exist but could not be fully
+// verified, and blocked links were never requested, so they are reported
+// by status only.
+func IsInaccessible(link LinkDetail) bool {
+	switch link.Status {
+	case LinkStatusBroken, LinkStatusError, LinkStatusTimeout, LinkStatusInvalid:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var fastRetryRules = LinkCheckRules{
+	FallbackStatusCodes:   DefaultLinkCheckRules.FallbackStatusCodes,
+	RestrictedStatusCodes: DefaultLinkCheckRules.RestrictedStatusCodes,
+	RetryStatusCodes:      DefaultLinkCheckRules.RetryStatusCodes,
+	MaxRetries:            2,
+	RetryBackoff:          time.Millisecond,
+	MaxRetryAfter:         10 * time.Millisecond,
+}
+
+func TestCheckLink_FallsBackToRangedGet(t *testing.T) {
+	var rangeHeader string
+	fetcher := FetcherFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodHead {
+			return newResponse(req, http.StatusMethodNotAllowed, nil, nil), nil
+		}
+		rangeHeader = req.Header.Get("Range")
+		return newResponse(req, http.StatusPartialContent, nil, []byte("<")), nil
+	})
+
+	outcome := checkLink(context.Background(), fetcher, Options{linkRules: &fastRetryRules}, "https://example.org/")
+
+	assert.Equal(t, LinkStatusOK, outcome.Status)
+	assert.Equal(t, http.MethodGet, outcome.Method)
+	assert.Equal(t, 2, outcome.Attempts)
+	assert.Equal(t, http.StatusPartialContent, outcome.StatusCode)
+	assert.Equal(t, "bytes=0-0", rangeHeader)
+}
+
+func TestCheckLink_Classification(t *testing.T) {
+	tests := []struct {
+		name       string
+		headStatus int
+		getStatus  int
+		rules      LinkCheckRules
+		status     string
+		errorKind  string
+	}{
+		{name: "OK", headStatus: 200, status: LinkStatusOK},
+		{name: "Not found is broken", headStatus: 404, status: LinkStatusBroken, errorKind: LinkErrorHTTPStatus},
+		{name: "Unauthorized is restricted", headStatus: 401, status: LinkStatusRestricted},
+		{name: "Forbidden after GET is restricted", headStatus: 403, getStatus: 403, status: LinkStatusRestricted},
+		{name: "Forbidden HEAD but GET works", headStatus: 403, getStatus: 200, status: LinkStatusOK},
+		{name: "Not implemented HEAD and missing page", headStatus: 501, getStatus: 404, status: LinkStatusBroken, errorKind: LinkErrorHTTPStatus},
+		{name: "Still rate limited after retries", headStatus: 429, status: LinkStatusRateLimited, errorKind: LinkErrorHTTPStatus},
+		{
+			name:       "Forbidden can be configured as broken",
+			headStatus: 403,
+			getStatus:  403,
+			rules:      LinkCheckRules{RestrictedStatusCodes: []int{}},
+			status:     LinkStatusBroken,
+			errorKind:  LinkErrorHTTPStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := fastRetryRules
+			if tt.rules.RestrictedStatusCodes != nil {
+				rules.RestrictedStatusCodes = tt.rules.RestrictedStatusCodes
+			}
+			fetcher := FetcherFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodHead {
+					return newResponse(req, tt.headStatus, nil, nil), nil
+				}
+				return newResponse(req, tt.getStatus, nil, nil), nil
+			})
+
+			outcome := checkLink(context.Background(), fetcher, Options{linkRules: &rules}, "https://example.org/")
+
+			assert.Equal(t, tt.status, outcome.Status)
+			assert.Equal(t, tt.errorKind, outcome.ErrorKind)
+		})
+	}
+}
+
+func TestCheckLink_RetriesHonouringRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	fetcher := FetcherFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) < 3 {
+			header := http.Header{}
+			header.Set("Retry-After", "0")
+			return newResponse(req, http.StatusServiceUnavailable, header, nil), nil
+		}
+		return newResponse(req, http.StatusOK, nil, nil), nil
+	})
+
+	outcome := checkLink(context.Background(), fetcher, Options{linkRules: &fastRetryRules}, "https://example.org/")
+
+	assert.Equal(t, LinkStatusOK, outcome.Status)
+	assert.Equal(t, 3, outcome.Attempts)
+}
+
+func TestRetryDelay(t *testing.T) {
+	rules := LinkCheckRules{RetryBackoff: 100 * time.Millisecond, MaxRetryAfter: 5 * time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, retryDelay("", 0, rules))
+	assert.Equal(t, 400*time.Millisecond, retryDelay("", 2, rules))
+	assert.Equal(t, 2*time.Second, retryDelay("2", 0, rules))
+	assert.Equal(t, 5*time.Second, retryDelay("120", 0, rules))
+	assert.Equal(t, time.Duration(0), retryDelay(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, rules))
+}
+
+func TestAnalyze_RestrictedLinksAreNotInaccessible(t *testing.T) {
+	pageUrl := "https://example.com/"
+	d := DefaultAnalyzerService{
+		Fetcher: NewFixtureFetcher(map[string]Fixture{
+			pageUrl:                         {Body: `<a href="https://intranet.example.org/">intranet</a>`},
+			"https://intranet.example.org/": {StatusCode: http.StatusUnauthorized},
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, analyze.InaccessibleLinks)
+	assert.Equal(t, 1, analyze.LinkStatusCounts[LinkStatusRestricted])
+
+	analyze, err = d.Analyze(context.Background(), pageUrl, WithRestrictedStatusCodes())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, analyze.InaccessibleLinks)
+	assert.Equal(t, 1, analyze.LinkStatusCounts[LinkStatusBroken])
+}
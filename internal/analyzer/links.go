@@ -0,0 +1,333 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"golang.org/x/net/html"
+)
+
+func linksCheck(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	result.LinkCheckLimits = page.Options.LinkCheckLimits
+	links := linksAnalyzer(ctx, page, pageURLs(result))
+	result.InternalLinks, result.ExternalLinks, result.InaccessibleLinks = summarizeLinks(links)
+	result.InaccessibleInternalLinks = countInaccessible(links, LinkInternal)
+	result.InaccessibleExternalLinks = countInaccessible(links, LinkExternal)
+	result.LinkStatusCounts = countStatuses(links)
+	if page.Options.IncludeLinks {
+		result.Links = links
+	}
+	result.Warnings = append(result.Warnings, linkCheckIssues(links, page.Options.checkInternalLinks())...)
+	return nil, ctx.Err()
+}
+
+// linkCheckIssues reports links whose check did not give an answer: those
+// still pending when the analysis stopped, and those that timed out, failed
+// or were rate limited. The counts alone hide them among broken links.
+func linkCheckIssues(links []LinkDetail, checkInternal bool) []Issue {
+	pending := 0
+	failed := make(map[string]int)
+	for _, link := range links {
+		switch link.Status {
+		case LinkStatusUnchecked:
+			if isCheckable(link, checkInternal) {
+				pending++
+			}
+		case LinkStatusTimeout, LinkStatusError, LinkStatusRateLimited:
+			failed[link.Status]++
+		}
+	}
+
+	var issues []Issue
+	if pending > 0 {
+		issues = append(issues, Issue{
+			Code:    IssueLinksUnchecked,
+			Message: fmt.Sprintf("%d link(s) were not checked before the analysis stopped", pending),
+		})
+	}
+	if len(failed) > 0 {
+		var parts []string
+		total := 0
+		for _, status := range []string{LinkStatusTimeout, LinkStatusError, LinkStatusRateLimited} {
+			if failed[status] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", failed[status], status))
+				total += failed[status]
+			}
+		}
+		issues = append(issues, Issue{
+			Code:    IssueLinkCheckFailed,
+			Message: fmt.Sprintf("%d link(s) could not be checked: %s", total, strings.Join(parts, ", ")),
+		})
+	}
+	return issues
+}
+
+// pageURLs lists the addresses of the analyzed page: the requested URL,
+// every hop of its redirect chain and the final URL.
+func pageURLs(result *PageAnalysisResponse) []string {
+	urls := []string{result.URL}
+	if result.Redirects != nil {
+		for _, hop := range result.Redirects.Hops {
+			urls = append(urls, hop.URL)
+		}
+	}
+	return append(urls, result.FinalURL)
+}
+
+// linksAnalyzer describes and checks the links of page. Links to any of
+// self, or to the URL of page, are links to the page itself.
+func linksAnalyzer(ctx context.Context, page *Page, self []string) []LinkDetail {
+
+	var links []LinkDetail
+	var wg sync.WaitGroup
+
+	baseUrl := page.URL
+	limiter := newLinkLimiter(page.Options.LinkCheckLimits)
+	positions := newPositionMatcher(page.Body)
+
+	page.Document.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, exists := s.Attr("href")
+		line, column := positions.next(href)
+
+		if !exists || href == "" || href == "#" {
+			return
+		}
+
+		link := LinkDetail{
+			Status: LinkStatusUnchecked,
+			Href:   href,
+			Text:   strings.Join(strings.Fields(s.Text()), " "),
+			Rel:    s.AttrOr("rel", ""),
+			Target: s.AttrOr("target", ""),
+			Line:   line,
+			Column: column,
+		}
+
+		linkUrl, err := url.Parse(href)
+
+		if err != nil {
+			log.Printf("[ERROR] Failed to parse link href: %s, error: %v", href, err)
+			link.Classification = LinkInvalid
+			link.Status = LinkStatusInvalid
+			link.ErrorKind = LinkErrorInvalidURL
+			links = append(links, link)
+			return
+		}
+
+		if !linkUrl.IsAbs() {
+			linkUrl = baseUrl.ResolveReference(linkUrl)
+		}
+		link.URL = linkUrl.String()
+
+		if linkUrl.Host == baseUrl.Host {
+			link.Classification = LinkInternal
+		} else {
+			link.Classification = LinkExternal
+		}
+		links = append(links, link)
+	})
+
+	checkInternal := page.Options.checkInternalLinks()
+	targets := make(map[string][]int)
+	var order []string
+	for i, link := range links {
+		if isCheckable(link, checkInternal) {
+			key := targetKey(link.URL)
+			if _, seen := targets[key]; !seen {
+				order = append(order, key)
+			}
+			targets[key] = append(targets[key], i)
+		}
+	}
+
+	var progressMu sync.Mutex
+	checked, total := 0, 0
+	for _, indexes := range targets {
+		total += len(indexes)
+	}
+	record := func(outcome linkOutcome, indexes []int) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		for _, i := range indexes {
+			outcome.apply(&links[i])
+			checked++
+			link := links[i]
+			page.Options.emit(ProgressEvent{Type: EventLink, Link: &link, Checked: checked, Total: total})
+		}
+	}
+
+	// The analyzed page itself was just fetched successfully, so links
+	// back to it (including "#section" anchors and the URLs it redirected
+	// from) are ok without a request of their own, and are not marked as
+	// checked.
+	for _, self := range append(self, baseUrl.String()) {
+		key := targetKey(self)
+		if indexes, ok := targets[key]; ok {
+			record(linkOutcome{Status: LinkStatusOK}, indexes)
+			delete(targets, key)
+		}
+	}
+
+	// A fixed pool of workers checks the targets in document order; the
+	// limiter spaces and bounds the checks of each host.
+	queue := make(chan []int)
+	for w := 0; w < min(page.Options.LinkCheckLimits.Workers, len(targets)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for indexes := range queue {
+				link := links[indexes[0]].URL
+				linkUrl, _ := url.Parse(link)
+				release, err := limiter.acquire(ctx, linkUrl.Host)
+				if err != nil {
+					continue
+				}
+				outcome := checkLink(ctx, page.Fetcher, page.Options, link)
+				release()
+				if outcome.ErrorKind != "" {
+					log.Printf("[DEBUG] Link inaccessible: %s", link)
+				}
+				record(outcome, indexes)
+			}
+		}()
+	}
+
+feed:
+	for _, key := range order {
+		indexes, ok := targets[key]
+		if !ok {
+			continue
+		}
+		select {
+		case queue <- indexes:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return links
+}
+
+// isCheckable reports whether the link checker requests link. Only http(s)
+// links are requested: a file: or data: link on a page must not make the
+// analyzer read local files, and mailto: or tel: links have nothing to
+// request. The others stay unchecked.
+func isCheckable(link LinkDetail, checkInternal bool) bool {
+	if link.Classification != LinkExternal && !(link.Classification == LinkInternal && checkInternal) {
+		return false
+	}
+	scheme, _, _ := strings.Cut(link.URL, ":")
+	return isHTTPScheme(scheme)
+}
+
+// targetKey identifies the resource a link points at; links differing only
+// in their fragment are checked once.
+func targetKey(link string) string {
+	target, _, _ := strings.Cut(link, "#")
+	return target
+}
+
+func summarizeLinks(links []LinkDetail) (int, int, int) {
+	var internalCount, externalCount, inaccessibleCount int
+	for _, link := range links {
+		switch link.Classification {
+		case LinkInternal:
+			internalCount++
+		case LinkExternal:
+			externalCount++
+		}
+		if IsInaccessible(link) {
+			inaccessibleCount++
+		}
+	}
+	return internalCount, externalCount, inaccessibleCount
+}
+
+func countStatuses(links []LinkDetail) map[string]int {
+	counts := make(map[string]int)
+	for _, link := range links {
+		counts[link.Status]++
+	}
+	return counts
+}
+
+func countInaccessible(links []LinkDetail, classification string) int {
+	count := 0
+	for _, link := range links {
+		if link.Classification == classification && IsInaccessible(link) {
+			count++
+		}
+	}
+	return count
+}
+
+// positionMatcher finds where each <a href> of the document starts in the
+// raw page source. The tokenizer sees the tags in source order, which is
+// also the order goquery returns them in, so links are matched up by
+// walking both lists and comparing href values.
+type positionMatcher struct {
+	anchors []anchorPosition
+	cursor  int
+}
+
+type anchorPosition struct {
+	href   string
+	line   int
+	column int
+}
+
+func newPositionMatcher(body []byte) *positionMatcher {
+	z := html.NewTokenizer(bytes.NewReader(body))
+	line, column := 1, 1
+	var anchors []anchorPosition
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return &positionMatcher{anchors: anchors}
+		}
+		raw := z.Raw()
+		newlines := bytes.Count(raw, []byte("\n"))
+		tail := raw[bytes.LastIndexByte(raw, '\n')+1:]
+
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			if name, hasAttr := z.TagName(); string(name) == "a" && hasAttr {
+				for {
+					key, val, more := z.TagAttr()
+					if string(key) == "href" {
+						anchors = append(anchors, anchorPosition{href: string(val), line: line, column: column})
+						break
+					}
+					if !more {
+						break
+					}
+				}
+			}
+		}
+
+		if newlines > 0 {
+			line += newlines
+			column = 1
+		}
+		column += utf8.RuneCount(tail)
+	}
+}
+
+func (m *positionMatcher) next(href string) (int, int) {
+	for i := m.cursor; i < len(m.anchors); i++ {
+		if m.anchors[i].href == href {
+			m.cursor = i + 1
+			return m.anchors[i].line, m.anchors[i].column
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,273 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const linkReportHTML = `<!DOCTYPE html>
+<html>
+<body>
+  <a href="/about">About   us</a>
+  <p>Read the <a href="https://docs.example.org/guide" rel="noopener" target="_blank">guide</a>
+     or the <a href="https://broken.example.org/">old docs</a>.</p>
+  <a href="http://[::1">bad</a>
+</body>
+</html>`
+
+func TestAnalyze_LinkDetails(t *testing.T) {
+	pageUrl := "https://example.com/"
+	d := DefaultAnalyzerService{
+		Fetcher: NewFixtureFetcher(map[string]Fixture{
+			pageUrl:                          {Body: linkReportHTML},
+			"https://docs.example.org/guide": {},
+			"https://broken.example.org/":    {StatusCode: http.StatusGone},
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithLinkDetails(), WithInternalLinkChecks(false))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, analyze.InternalLinks)
+	assert.Equal(t, 2, analyze.ExternalLinks)
+	assert.Equal(t, 2, analyze.InaccessibleLinks)
+	assert.Equal(t, []LinkDetail{
+		{
+			URL:            "https://example.com/about",
+			Href:           "/about",
+			Text:           "About us",
+			Classification: LinkInternal,
+			Status:         LinkStatusUnchecked,
+			Line:           4,
+			Column:         3,
+		},
+		{
+			URL:            "https://docs.example.org/guide",
+			Href:           "https://docs.example.org/guide",
+			Text:           "guide",
+			Rel:            "noopener",
+			Target:         "_blank",
+			Classification: LinkExternal,
+			Status:         LinkStatusOK,
+			Checked:        true,
+			Method:         http.MethodHead,
+			Attempts:       1,
+			StatusCode:     http.StatusOK,
+			Line:           5,
+			Column:         15,
+		},
+		{
+			URL:            "https://broken.example.org/",
+			Href:           "https://broken.example.org/",
+			Text:           "old docs",
+			Classification: LinkExternal,
+			Status:         LinkStatusBroken,
+			Checked:        true,
+			Method:         http.MethodHead,
+			Attempts:       1,
+			StatusCode:     http.StatusGone,
+			ErrorKind:      LinkErrorHTTPStatus,
+			Line:           6,
+			Column:         13,
+		},
+		{
+			Href:           "http://[::1",
+			Text:           "bad",
+			Classification: LinkInvalid,
+			Status:         LinkStatusInvalid,
+			ErrorKind:      LinkErrorInvalidURL,
+			Line:           7,
+			Column:         3,
+		},
+	}, withoutLatency(analyze.Links))
+}
+
+func TestAnalyze_LinkDetailsAreOptional(t *testing.T) {
+	pageUrl := "https://example.com/"
+	d := newFixtureService(pageUrl, Fixture{Body: linkReportHTML})
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.Nil(t, analyze.Links)
+}
+
+func TestAnalyze_ChecksInternalLinksOncePerTarget(t *testing.T) {
+	pageUrl := "https://example.com/"
+	html := `<a href="#top">top</a>
+		<a href="/docs">docs</a>
+		<a href="/docs#install">install</a>
+		<a href="https://example.com/docs">docs again</a>
+		<a href="/missing">missing</a>
+		<a href="https://other.example.org/missing">external missing</a>`
+
+	fixtures := NewFixtureFetcher(map[string]Fixture{
+		pageUrl:                    {Body: html},
+		"https://example.com/docs": {},
+	})
+	var requested []string
+	d := DefaultAnalyzerService{
+		LinkCheckLimits: LinkCheckLimits{Workers: 1},
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodHead {
+				requested = append(requested, req.URL.String())
+			}
+			return fixtures.Do(req)
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"https://example.com/docs",
+		"https://example.com/missing",
+		"https://other.example.org/missing",
+	}, requested)
+	assert.Equal(t, 5, analyze.InternalLinks)
+	assert.Equal(t, 1, analyze.ExternalLinks)
+	assert.Equal(t, 2, analyze.InaccessibleLinks)
+	assert.Equal(t, 1, analyze.InaccessibleInternalLinks)
+	assert.Equal(t, 1, analyze.InaccessibleExternalLinks)
+}
+
+func TestAnalyze_LinksToRedirectedPageAreSelfLinks(t *testing.T) {
+	pageUrl := "http://example.com/old"
+	html := `<a href="http://example.com/old">old</a>
+		<a href="https://example.com/new#top">top</a>
+		<a href="https://example.com/start">start</a>
+		<a href="https://example.com/other">other</a>`
+
+	fixtures := NewFixtureFetcher(map[string]Fixture{
+		pageUrl:                     redirectTo(http.StatusMovedPermanently, "https://example.com/start"),
+		"https://example.com/start": redirectTo(http.StatusFound, "/new"),
+		"https://example.com/new":   {Body: html},
+		"https://example.com/other": {},
+	})
+	var requested []string
+	d := DefaultAnalyzerService{
+		LinkCheckLimits: LinkCheckLimits{Workers: 1},
+		Fetcher: FetcherFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodHead {
+				requested = append(requested, req.URL.String())
+			}
+			return fixtures.Do(req)
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithLinkDetails())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/other"}, requested)
+	assert.Equal(t, 0, analyze.InaccessibleLinks)
+	for _, link := range analyze.Links[:3] {
+		// No request was made for the page itself, so none is reported.
+		assert.Equal(t, LinkStatusOK, link.Status, link.URL)
+		assert.False(t, link.Checked, link.URL)
+		assert.Empty(t, link.Method, link.URL)
+		assert.Zero(t, link.Attempts, link.URL)
+		assert.Zero(t, link.StatusCode, link.URL)
+	}
+	assert.Equal(t, LinkStatusOK, analyze.Links[3].Status)
+	assert.True(t, analyze.Links[3].Checked)
+}
+
+func TestAnalyze_OnlyHTTPLinksAreChecked(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "id_rsa")
+	assert.NoError(t, os.WriteFile(secret, []byte("key"), 0o600))
+	pageUrl := "https://example.com/"
+	html := `<a href="file://` + filepath.ToSlash(secret) + `">key</a>
+		<a href="data:text/plain,hi">data</a>
+		<a href="mailto:info@example.com">mail</a>
+		<a href="tel:+100">call</a>
+		<a href="https://example.org/">site</a>`
+
+	local := NewLocalFetcher()
+	var requested []string
+	fixtures := NewFixtureFetcher(map[string]Fixture{pageUrl: {Body: html}, "https://example.org/": {}})
+	local["https"] = FetcherFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		return fixtures.Do(req)
+	})
+	local["file"] = FetcherFunc(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("file link was requested: %s", req.URL)
+		return FileFetcher{}.Do(req)
+	})
+	d := DefaultAnalyzerService{LinkCheckLimits: LinkCheckLimits{Workers: 1}, Fetcher: local}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithLinkDetails())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pageUrl, "https://example.org/"}, requested)
+	for _, link := range analyze.Links[:4] {
+		assert.Equal(t, LinkStatusUnchecked, link.Status, link.URL)
+		assert.False(t, link.Checked, link.URL)
+	}
+	assert.Equal(t, LinkStatusOK, analyze.Links[4].Status)
+	assert.Empty(t, analyze.Warnings)
+}
+
+func TestAnalyze_InternalLinkChecksCanBeDisabled(t *testing.T) {
+	pageUrl := "https://example.com/"
+	d := DefaultAnalyzerService{
+		SkipInternalLinkChecks: true,
+		Fetcher:                NewFixtureFetcher(map[string]Fixture{pageUrl: {Body: `<a href="/missing">missing</a>`}}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, analyze.InaccessibleInternalLinks)
+
+	analyze, err = d.Analyze(context.Background(), pageUrl, WithInternalLinkChecks(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, analyze.InaccessibleInternalLinks)
+}
+
+func TestPositionMatcher_SkipsUnmatchedAnchors(t *testing.T) {
+	m := newPositionMatcher([]byte("<a href=\"/one\">1</a>\n<a href=\"/two\">2</a>"))
+
+	line, column := m.next("/two")
+	assert.Equal(t, 2, line)
+	assert.Equal(t, 1, column)
+
+	line, column = m.next("/one")
+	assert.Equal(t, 0, line)
+	assert.Equal(t, 0, column)
+}
+
+func withoutLatency(links []LinkDetail) []LinkDetail {
+	for i := range links {
+		links[i].LatencyMs = 0
+	}
+	return links
+}
+
+func TestLinkCheckIssues(t *testing.T) {
+	links := []LinkDetail{
+		{Classification: LinkExternal, Status: LinkStatusOK},
+		{Classification: LinkExternal, Status: LinkStatusBroken},
+		{Classification: LinkExternal, Status: LinkStatusTimeout},
+		{Classification: LinkExternal, Status: LinkStatusTimeout},
+		{Classification: LinkExternal, Status: LinkStatusRateLimited},
+		{URL: "https://example.org/", Classification: LinkExternal, Status: LinkStatusUnchecked},
+		{URL: "https://example.com/a", Classification: LinkInternal, Status: LinkStatusUnchecked},
+		{URL: "mailto:info@example.com", Classification: LinkExternal, Status: LinkStatusUnchecked},
+	}
+
+	assert.Equal(t, []Issue{
+		{Code: IssueLinksUnchecked, Message: "2 link(s) were not checked before the analysis stopped"},
+		{Code: IssueLinkCheckFailed, Message: "3 link(s) could not be checked: 2 timeout, 1 rate_limited"},
+	}, linkCheckIssues(links, true))
+	// Internal and non-http(s) links that were never meant to be checked
+	// are not pending.
+	assert.Equal(t, IssueLinksUnchecked, linkCheckIssues(links, false)[0].Code)
+	assert.Equal(t, "1 link(s) were not checked before the analysis stopped", linkCheckIssues(links, false)[0].Message)
+	assert.Empty(t, linkCheckIssues(links[:2], true))
+}
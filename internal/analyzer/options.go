@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// Options are the per-request settings of a single analysis.
+type Options struct {
+	EnabledChecks   []string
+	DisabledChecks  []string
+	Timeout         time.Duration
+	LinkCheckLimits model.LinkCheckLimits
+	IncludeLinks    bool
+	// CheckInternalLinks overrides DefaultAnalyzerService.SkipInternalLinkChecks
+	// when set.
+	CheckInternalLinks *bool
+	// RestrictedStatusCodes overrides LinkCheckRules.RestrictedStatusCodes
+	// when non-nil.
+	RestrictedStatusCodes []int
+	// UserAgent replaces the service User-Agent and applies to the page
+	// fetch and to link checks. Headers are added to the service headers and
+	// only sent with the page fetch, so credentials never reach the hosts
+	// the page links to.
+	UserAgent string
+	Headers   map[string]string
+	Progress  func(ProgressEvent)
+	// Policy is evaluated against the finished result.
+	Policy *model.Policy
+
+	linkRules    *LinkCheckRules
+	maxRedirects int
+}
+
+type Option func(*Options)
+
+func WithChecks(names ...string) Option {
+	return func(o *Options) {
+		o.EnabledChecks = append(o.EnabledChecks, names...)
+	}
+}
+
+func WithoutChecks(names ...string) Option {
+	return func(o *Options) {
+		o.DisabledChecks = append(o.DisabledChecks, names...)
+	}
+}
+
+// WithTimeout bounds the whole analysis, on top of any deadline already
+// carried by the context passed to Analyze.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = timeout
+	}
+}
+
+// WithLinkCheckLimits tightens the link-check limits of the service for a
+// single analysis. Zero fields keep the service setting.
+func WithLinkCheckLimits(limits model.LinkCheckLimits) Option {
+	return func(o *Options) {
+		o.LinkCheckLimits = limits
+	}
+}
+
+// WithLinkDetails adds the per-link report to the response.
+func WithLinkDetails() Option {
+	return func(o *Options) {
+		o.IncludeLinks = true
+	}
+}
+
+func WithInternalLinkChecks(enabled bool) Option {
+	return func(o *Options) {
+		o.CheckInternalLinks = &enabled
+	}
+}
+
+func (o Options) checkInternalLinks() bool {
+	return o.CheckInternalLinks == nil || *o.CheckInternalLinks
+}
+
+func WithRestrictedStatusCodes(codes ...int) Option {
+	return func(o *Options) {
+		o.RestrictedStatusCodes = append([]int{}, codes...)
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(o *Options) {
+		o.UserAgent = userAgent
+	}
+}
+
+func WithHeaders(headers map[string]string) Option {
+	return func(o *Options) {
+		if o.Headers == nil {
+			o.Headers = make(map[string]string, len(headers))
+		}
+		for name, value := range headers {
+			o.Headers[name] = value
+		}
+	}
+}
+
+func WithPolicy(policy model.Policy) Option {
+	return func(o *Options) {
+		o.Policy = &policy
+	}
+}
+
+func (o Options) setRequestHeaders(req *http.Request) {
+	for name, value := range o.Headers {
+		req.Header.Set(name, value)
+	}
+	o.setLinkHeaders(req)
+}
+
+// setLinkHeaders sets the headers of a link check, which carry the
+// User-Agent but none of the extra headers.
+func (o Options) setLinkHeaders(req *http.Request) {
+	if o.UserAgent != "" {
+		req.Header.Set("User-Agent", o.UserAgent)
+	}
+}
+
+func (o Options) linkCheckRules() LinkCheckRules {
+	rules := DefaultLinkCheckRules
+	if o.linkRules != nil {
+		rules = *o.linkRules
+	}
+	if o.RestrictedStatusCodes != nil {
+		rules.RestrictedStatusCodes = o.RestrictedStatusCodes
+	}
+	return rules
+}
+
+// RequestOptions turns the settings of an API request into analysis
+// options.
+func RequestOptions(request model.PageAnalysisRequest) []Option {
+	var options []Option
+	if len(request.Checks) > 0 {
+		options = append(options, WithChecks(request.Checks...))
+	}
+	if len(request.DisabledChecks) > 0 {
+		options = append(options, WithoutChecks(request.DisabledChecks...))
+	}
+	if request.TimeoutMs > 0 {
+		options = append(options, WithTimeout(time.Duration(request.TimeoutMs)*time.Millisecond))
+	}
+	if request.LinkCheck != nil {
+		options = append(options, WithLinkCheckLimits(*request.LinkCheck))
+	}
+	if request.IncludeLinks {
+		options = append(options, WithLinkDetails())
+	}
+	if request.CheckInternalLinks != nil {
+		options = append(options, WithInternalLinkChecks(*request.CheckInternalLinks))
+	}
+	if request.RestrictedStatusCodes != nil {
+		options = append(options, WithRestrictedStatusCodes(request.RestrictedStatusCodes...))
+	}
+	if request.UserAgent != "" {
+		options = append(options, WithUserAgent(request.UserAgent))
+	}
+	if len(request.Headers) > 0 {
+		options = append(options, WithHeaders(request.Headers))
+	}
+	if request.Policy != nil {
+		options = append(options, WithPolicy(*request.Policy))
+	}
+	return options
+}
+
+func newOptions(options []Option) Options {
+	var o Options
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
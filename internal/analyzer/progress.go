@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"sync"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// Progress event types, in the order an analysis emits them. A check event
+// is sent after every check; one link event per link that needed a request.
+const (
+	EventFetched = "fetched"
+	EventParsed  = "parsed"
+	EventCheck   = "check"
+	EventLink    = "link"
+	EventDone    = "done"
+)
+
+// ProgressEvent reports a finished phase of an analysis. Result points at
+// the response being built and is only safe to read during the callback.
+type ProgressEvent struct {
+	Type       string                `json:"type"`
+	URL        string                `json:"url,omitempty"`
+	StatusCode int                   `json:"statusCode,omitempty"`
+	Bytes      int                   `json:"bytes,omitempty"`
+	Check      string                `json:"check,omitempty"`
+	Link       *LinkDetail           `json:"link,omitempty"`
+	Checked    int                   `json:"checked,omitempty"`
+	Total      int                   `json:"total,omitempty"`
+	Result     *PageAnalysisResponse `json:"result,omitempty"`
+}
+
+// WithProgress calls progress as the analysis advances. Calls never overlap,
+// but link events come from the link-check goroutines, so progress should
+// return quickly.
+func WithProgress(progress func(ProgressEvent)) Option {
+	return func(o *Options) {
+		o.Progress = progress
+	}
+}
+
+func (o Options) emit(event ProgressEvent) {
+	if o.Progress != nil {
+		o.Progress(event)
+	}
+}
+
+func serializeProgress(progress func(ProgressEvent)) func(ProgressEvent) {
+	if progress == nil {
+		return nil
+	}
+	var mu sync.Mutex
+	return func(event ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress(event)
+	}
+}
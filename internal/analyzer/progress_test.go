@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze_ShouldReportProgress(t *testing.T) {
+	pageUrl := "https://example.com/"
+	service := DefaultAnalyzerService{Fetcher: NewFixtureFetcher(map[string]Fixture{
+		pageUrl: {Body: `<html><head><title>Progress</title></head><body><h1>One</h1>
+			<a href="/about">About</a>
+			<a href="/about#team">Team</a>
+			<a href="https://other.example.org/gone">Gone</a>
+			<a href="#top">Top</a>
+		</body></html>`},
+		"https://example.com/about": {},
+	})}
+
+	var events []ProgressEvent
+	var headings map[string]int
+	result, err := service.Analyze(context.Background(), pageUrl, WithProgress(func(event ProgressEvent) {
+		if event.Type == EventCheck && event.Check == CheckHeadings {
+			headings = map[string]int{"h1": event.Result.HeadingCounts["h1"]}
+		}
+		events = append(events, event)
+	}))
+
+	assert.NoError(t, err)
+	var types []string
+	var linkStatuses = make(map[string]string)
+	for _, event := range events {
+		types = append(types, event.Type)
+		if event.Type == EventLink {
+			linkStatuses[event.Link.Href] = event.Link.Status
+			assert.Equal(t, 4, event.Total)
+		}
+	}
+	assert.Equal(t, []string{
+		EventFetched, EventParsed,
+		EventCheck, EventCheck, EventCheck, EventCheck, EventCheck, EventCheck,
+		EventLink, EventLink, EventLink, EventLink,
+		EventCheck, EventDone,
+	}, types)
+	assert.Equal(t, 4, events[11].Checked)
+	assert.Equal(t, map[string]int{"h1": 1}, headings)
+	assert.Equal(t, map[string]string{
+		"/about":                         LinkStatusOK,
+		"/about#team":                    LinkStatusOK,
+		"https://other.example.org/gone": LinkStatusBroken,
+		"#top":                           LinkStatusOK,
+	}, linkStatuses)
+	assert.Equal(t, "Progress", events[len(events)-1].Result.Title)
+	assert.Equal(t, result.InaccessibleLinks, events[len(events)-1].Result.InaccessibleLinks)
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+const DefaultMaxRedirects = 10
+
+var (
+	ErrRedirectLoop     = errors.New("redirect loop")
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrRedirectScheme   = errors.New("redirect to an unsupported scheme")
+)
+
+// followRedirects sends req and follows any redirects itself, so every hop
+// is recorded. Fetchers are expected to return redirect responses as they
+// are; HTTPFetcher does. When the chain loops or grows past maxRedirects
+// the chain so far is returned together with ErrRedirectLoop or
+// ErrTooManyRedirects. Like net/http, an http(s) URL may only redirect to
+// another http(s) URL; any other scheme fails with ErrRedirectScheme, so a
+// remote page cannot point the fetcher at a local file.
+func followRedirects(fetcher Fetcher, req *http.Request, maxRedirects int) (*http.Response, *RedirectChain, error) {
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	chain := &RedirectChain{}
+	visited := map[string]bool{req.URL.String(): true}
+
+	for {
+		resp, err := fetcher.Do(req)
+		if err != nil {
+			return nil, chain, err
+		}
+
+		location := resp.Header.Get("Location")
+		if !isRedirect(resp.StatusCode) || location == "" {
+			chain.FinalURL = req.URL.String()
+			return resp, chain, nil
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxFallbackBody))
+		resp.Body.Close()
+
+		next, err := req.URL.Parse(location)
+		if err != nil {
+			return nil, chain, fmt.Errorf("invalid redirect location %q: %w", location, err)
+		}
+		chain.Hops = append(chain.Hops, RedirectHop{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Location:   next.String(),
+		})
+		chain.HopCount = len(chain.Hops)
+		if req.URL.Scheme == "https" && next.Scheme == "http" {
+			chain.HTTPSDowngrade = true
+		}
+		if isHTTPScheme(req.URL.Scheme) && !isHTTPScheme(next.Scheme) {
+			chain.FinalURL = req.URL.String()
+			return nil, chain, fmt.Errorf("%w: %s", ErrRedirectScheme, next.Scheme)
+		}
+
+		if visited[next.String()] {
+			chain.Loop = true
+			chain.FinalURL = next.String()
+			return nil, chain, ErrRedirectLoop
+		}
+		if chain.HopCount > maxRedirects {
+			chain.TooManyHops = true
+			chain.FinalURL = next.String()
+			return nil, chain, ErrTooManyRedirects
+		}
+		visited[next.String()] = true
+
+		req = redirectRequest(req, next)
+	}
+}
+
+func isHTTPScheme(scheme string) bool {
+	return scheme == "http" || scheme == "https"
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// redirectRequest builds the request for the next hop. Credentials are not
+// forwarded to another host.
+func redirectRequest(req *http.Request, next *url.URL) *http.Request {
+	nextReq := req.Clone(req.Context())
+	nextReq.URL = next
+	nextReq.Host = ""
+	if next.Host != req.URL.Host {
+		nextReq.Header.Del("Authorization")
+		nextReq.Header.Del("Cookie")
+	}
+	return nextReq
+}
+
+// chainOrNil drops empty chains so links and pages that were not
+// redirected carry no redirect section.
+func chainOrNil(chain *RedirectChain) *RedirectChain {
+	if chain == nil || chain.HopCount == 0 {
+		return nil
+	}
+	return chain
+}
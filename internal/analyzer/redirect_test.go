@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func redirectTo(statusCode int, location string) Fixture {
+	header := http.Header{}
+	header.Set("Location", location)
+	return Fixture{StatusCode: statusCode, Header: header}
+}
+
+func TestAnalyze_PageRedirectChain(t *testing.T) {
+	d := DefaultAnalyzerService{
+		Fetcher: NewFixtureFetcher(map[string]Fixture{
+			"https://example.com/old":      redirectTo(http.StatusMovedPermanently, "/new"),
+			"https://example.com/new":      redirectTo(http.StatusFound, "http://www.example.com/new"),
+			"http://www.example.com/new":   {Body: `<title>Moved</title><a href="/about">about</a>`},
+			"http://www.example.com/about": {},
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), "https://example.com/old")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/old", analyze.URL)
+	assert.Equal(t, "http://www.example.com/new", analyze.FinalURL)
+	assert.Equal(t, "Moved", analyze.Title)
+	assert.Equal(t, 1, analyze.InternalLinks)
+	assert.Equal(t, 0, analyze.InaccessibleLinks)
+	assert.Equal(t, &RedirectChain{
+		Hops: []RedirectHop{
+			{URL: "https://example.com/old", StatusCode: http.StatusMovedPermanently, Location: "https://example.com/new"},
+			{URL: "https://example.com/new", StatusCode: http.StatusFound, Location: "http://www.example.com/new"},
+		},
+		HopCount:       2,
+		FinalURL:       "http://www.example.com/new",
+		HTTPSDowngrade: true,
+	}, analyze.Redirects)
+}
+
+func TestAnalyze_PageWithoutRedirects(t *testing.T) {
+	pageUrl := "https://example.com/"
+	d := newFixtureService(pageUrl, Fixture{Body: "<title>Home</title>"})
+
+	analyze, err := d.Analyze(context.Background(), pageUrl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, pageUrl, analyze.FinalURL)
+	assert.Nil(t, analyze.Redirects)
+}
+
+func TestAnalyze_PageRedirectLoop(t *testing.T) {
+	d := DefaultAnalyzerService{
+		Fetcher: NewFixtureFetcher(map[string]Fixture{
+			"https://example.com/a": redirectTo(http.StatusFound, "/b"),
+			"https://example.com/b": redirectTo(http.StatusFound, "/a"),
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), "https://example.com/a")
+
+	assert.ErrorIs(t, err, ErrRedirectLoop)
+	assert.Equal(t, "failed to fetch the webpage: redirect loop", err.Error())
+	chain := &RedirectChain{
+		Hops: []RedirectHop{
+			{URL: "https://example.com/a", StatusCode: http.StatusFound, Location: "https://example.com/b"},
+			{URL: "https://example.com/b", StatusCode: http.StatusFound, Location: "https://example.com/a"},
+		},
+		HopCount: 2,
+		FinalURL: "https://example.com/a",
+		Loop:     true,
+	}
+	var analysisErr *Error
+	assert.ErrorAs(t, err, &analysisErr)
+	assert.Equal(t, chain, analysisErr.Redirects)
+	assert.Equal(t, PageAnalysisResponse{URL: "https://example.com/a", FinalURL: "https://example.com/a", Redirects: chain}, analyze)
+}
+
+func TestAnalyze_PageTooManyRedirects(t *testing.T) {
+	d := DefaultAnalyzerService{
+		MaxRedirects: 1,
+		Fetcher: NewFixtureFetcher(map[string]Fixture{
+			"https://example.com/1": redirectTo(http.StatusFound, "/2"),
+			"https://example.com/2": redirectTo(http.StatusFound, "/3"),
+			"https://example.com/3": redirectTo(http.StatusFound, "/4"),
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), "https://example.com/1")
+
+	assert.ErrorIs(t, err, ErrTooManyRedirects)
+	assert.Equal(t, CodeFetchFailed, ErrorCode(err))
+	assert.True(t, analyze.Redirects.TooManyHops)
+	assert.Equal(t, 2, analyze.Redirects.HopCount)
+	assert.Equal(t, "https://example.com/3", analyze.FinalURL)
+}
+
+func TestAnalyze_LinkRedirects(t *testing.T) {
+	pageUrl := "https://example.com/"
+	d := DefaultAnalyzerService{
+		MaxRedirects: 2,
+		Fetcher: NewFixtureFetcher(map[string]Fixture{
+			pageUrl: {Body: `<a href="https://moved.example.org/">moved</a>
+				<a href="https://far.example.org/1">far</a>`},
+			"https://moved.example.org/":     redirectTo(http.StatusPermanentRedirect, "https://moved.example.org/home"),
+			"https://moved.example.org/home": {},
+			"https://far.example.org/1":      redirectTo(http.StatusFound, "/2"),
+			"https://far.example.org/2":      redirectTo(http.StatusFound, "/3"),
+			"https://far.example.org/3":      redirectTo(http.StatusFound, "/4"),
+		}),
+	}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithLinkDetails())
+
+	assert.NoError(t, err)
+	assert.Len(t, analyze.Links, 2)
+
+	moved := analyze.Links[0]
+	assert.Equal(t, LinkStatusOK, moved.Status)
+	assert.Equal(t, 1, moved.Redirects.HopCount)
+	assert.Equal(t, "https://moved.example.org/home", moved.Redirects.FinalURL)
+
+	far := analyze.Links[1]
+	assert.Equal(t, LinkStatusBroken, far.Status)
+	assert.Equal(t, LinkErrorRedirect, far.ErrorKind)
+	assert.True(t, far.Redirects.TooManyHops)
+	assert.Equal(t, 3, far.Redirects.HopCount)
+}
+
+func TestAnalyze_PageRedirectToFileIsRefused(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "creds.html")
+	assert.NoError(t, os.WriteFile(secret, []byte("<title>TOP SECRET</title>"), 0o644))
+	fileUrl := "file://" + filepath.ToSlash(secret)
+
+	local := NewLocalFetcher()
+	fixtures := NewFixtureFetcher(map[string]Fixture{
+		"https://example.com/": redirectTo(http.StatusFound, fileUrl),
+	})
+	local["https"] = fixtures
+	d := DefaultAnalyzerService{Fetcher: local}
+
+	analyze, err := d.Analyze(context.Background(), "https://example.com/")
+
+	assert.ErrorIs(t, err, ErrRedirectScheme)
+	assert.Equal(t, CodeFetchFailed, ErrorCode(err))
+	assert.Empty(t, analyze.Title)
+	assert.Equal(t, "https://example.com/", analyze.FinalURL)
+	assert.Equal(t, fileUrl, analyze.Redirects.Hops[0].Location)
+}
+
+func TestAnalyze_LinkRedirectToFileIsRefused(t *testing.T) {
+	pageUrl := "https://example.com/"
+	local := NewLocalFetcher()
+	local["https"] = NewFixtureFetcher(map[string]Fixture{
+		pageUrl:                     {Body: `<a href="/moved">moved</a>`},
+		"https://example.com/moved": redirectTo(http.StatusFound, "file:///etc/hostname"),
+	})
+	d := DefaultAnalyzerService{Fetcher: local}
+
+	analyze, err := d.Analyze(context.Background(), pageUrl, WithLinkDetails())
+
+	assert.NoError(t, err)
+	assert.Equal(t, LinkErrorRedirect, analyze.Links[0].ErrorKind)
+	assert.NotEqual(t, LinkStatusOK, analyze.Links[0].Status)
+}
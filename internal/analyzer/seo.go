@@ -0,0 +1,232 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// Recommended lengths of the title and meta description, in characters.
+const (
+	minTitleLength       = 10
+	maxTitleLength       = 60
+	minDescriptionLength = 50
+	maxDescriptionLength = 160
+)
+
+// robotsValueDirectives are the robots directives that take a value after a
+// colon; any other "name:" prefix names the crawler a directive is for.
+var robotsValueDirectives = []string{"max-snippet", "max-image-preview", "max-video-preview", "unavailable_after"}
+
+func seoCheck(ctx context.Context, page *Page, result *PageAnalysisResponse) (any, error) {
+	seo := analyzeSEO(page.Document, page.URL, page.Header.Values("X-Robots-Tag"))
+	result.SEO = &seo
+	return nil, nil
+}
+
+// documentTitle is the text of the first title element of the head, with
+// whitespace collapsed as browsers do. Titles elsewhere, such as those of
+// inline SVG images, are not the document title.
+func documentTitle(doc *goquery.Document) string {
+	return collapseSpace(doc.Find("head > title").First().Text())
+}
+
+func analyzeSEO(doc *goquery.Document, base *url.URL, xRobotsTag []string) SEO {
+	seo := SEO{
+		Title:      documentTitle(doc),
+		XRobotsTag: xRobotsTag,
+		Indexable:  true,
+		Followable: true,
+	}
+	seo.TitleLength = utf8.RuneCountInString(seo.Title)
+
+	var issues []Issue
+	add := func(code string, format string, args ...any) {
+		issues = append(issues, Issue{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	descriptions := metaContents(doc, "description")
+	if len(descriptions) > 0 {
+		seo.Description = collapseSpace(descriptions[0])
+		seo.DescriptionLength = utf8.RuneCountInString(seo.Description)
+	}
+	robots := metaContents(doc, "robots")
+	seo.Robots = strings.Join(robots, ", ")
+	for _, value := range append(robots, xRobotsTag...) {
+		noindex, nofollow := robotsDirectives(value)
+		seo.Indexable = seo.Indexable && !noindex
+		seo.Followable = seo.Followable && !nofollow
+	}
+	if viewport := metaContents(doc, "viewport"); len(viewport) > 0 {
+		seo.Viewport = strings.TrimSpace(viewport[0])
+	}
+
+	canonicals := linksWithRel(doc, "canonical")
+	if len(canonicals) > 0 {
+		seo.Canonical = resolveURL(base, canonicals[0].AttrOr("href", ""))
+	}
+	if prev := linksWithRel(doc, "prev"); len(prev) > 0 {
+		seo.Prev = resolveURL(base, prev[0].AttrOr("href", ""))
+	}
+	if next := linksWithRel(doc, "next"); len(next) > 0 {
+		seo.Next = resolveURL(base, next[0].AttrOr("href", ""))
+	}
+	for _, link := range linksWithRel(doc, "alternate") {
+		if hreflang := strings.TrimSpace(link.AttrOr("hreflang", "")); hreflang != "" {
+			seo.Alternates = append(seo.Alternates, HreflangAlternate{
+				Hreflang: hreflang,
+				URL:      resolveURL(base, link.AttrOr("href", "")),
+			})
+		}
+	}
+	doc.Find("meta[http-equiv]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if !strings.EqualFold(strings.TrimSpace(s.AttrOr("http-equiv", "")), "refresh") {
+			return true
+		}
+		if refresh, ok := parseMetaRefresh(s.AttrOr("content", "")); ok {
+			if refresh.URL != "" {
+				refresh.URL = resolveURL(base, refresh.URL)
+			}
+			seo.Refresh = &refresh
+			return false
+		}
+		return true
+	})
+
+	titles := doc.Find("head > title").Length()
+	switch {
+	case seo.Title == "":
+		add(SEOIssueMissingTitle, "the page has no title")
+	case seo.TitleLength < minTitleLength:
+		add(SEOIssueTitleTooShort, "the title is %d characters long; aim for %d to %d", seo.TitleLength, minTitleLength, maxTitleLength)
+	case seo.TitleLength > maxTitleLength:
+		add(SEOIssueTitleTooLong, "the title is %d characters long and may be cut off after %d", seo.TitleLength, maxTitleLength)
+	}
+	if titles > 1 {
+		add(SEOIssueMultipleTitles, "the head has %d title elements", titles)
+	}
+	switch {
+	case seo.Description == "":
+		add(SEOIssueMissingDescription, "the page has no meta description")
+	case seo.DescriptionLength < minDescriptionLength:
+		add(SEOIssueDescriptionTooShort, "the meta description is %d characters long; aim for %d to %d", seo.DescriptionLength, minDescriptionLength, maxDescriptionLength)
+	case seo.DescriptionLength > maxDescriptionLength:
+		add(SEOIssueDescriptionTooLong, "the meta description is %d characters long and may be cut off after %d", seo.DescriptionLength, maxDescriptionLength)
+	}
+	if len(descriptions) > 1 {
+		add(SEOIssueMultipleDescriptions, "the page has %d meta descriptions", len(descriptions))
+	}
+	if seo.Description != "" && strings.EqualFold(seo.Description, seo.Title) {
+		add(SEOIssueDuplicateDescription, "the meta description repeats the title")
+	}
+	if !seo.Indexable {
+		add(SEOIssueNotIndexable, "search engines are asked not to index the page")
+	}
+	if len(canonicals) > 1 {
+		add(SEOIssueMultipleCanonicals, "the page has %d canonical links", len(canonicals))
+	}
+	if seo.Refresh != nil {
+		if seo.Refresh.URL != "" {
+			add(SEOIssueMetaRefresh, "a meta refresh redirects to %s after %d second(s)", seo.Refresh.URL, seo.Refresh.DelaySeconds)
+		} else {
+			add(SEOIssueMetaRefresh, "a meta refresh reloads the page every %d second(s)", seo.Refresh.DelaySeconds)
+		}
+	}
+	if seo.Viewport == "" {
+		add(SEOIssueMissingViewport, "the page has no viewport meta tag")
+	}
+	seo.Issues = issues
+	return seo
+}
+
+// metaContents returns the content of every <meta name="name">.
+func metaContents(doc *goquery.Document, name string) []string {
+	var contents []string
+	doc.Find("meta[name]").Each(func(_ int, s *goquery.Selection) {
+		if strings.EqualFold(strings.TrimSpace(s.AttrOr("name", "")), name) {
+			contents = append(contents, s.AttrOr("content", ""))
+		}
+	})
+	return contents
+}
+
+// linksWithRel returns the <link> elements whose rel contains rel.
+func linksWithRel(doc *goquery.Document, rel string) []*goquery.Selection {
+	var links []*goquery.Selection
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		for _, value := range strings.Fields(s.AttrOr("rel", "")) {
+			if strings.EqualFold(value, rel) {
+				links = append(links, s)
+				return
+			}
+		}
+	})
+	return links
+}
+
+// robotsDirectives reads a robots meta content or X-Robots-Tag value such
+// as "noindex, nofollow". Values for a single crawler, like
+// "googlebot: noindex", are skipped.
+func robotsDirectives(value string) (noindex bool, nofollow bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if name, _, found := strings.Cut(value, ":"); found && !strings.Contains(name, ",") && !isValueDirective(name) {
+		return false, false
+	}
+	for _, directive := range strings.Split(value, ",") {
+		switch strings.TrimSpace(directive) {
+		case "noindex":
+			noindex = true
+		case "nofollow":
+			nofollow = true
+		case "none":
+			noindex, nofollow = true, true
+		}
+	}
+	return noindex, nofollow
+}
+
+func isValueDirective(name string) bool {
+	name = strings.TrimSpace(name)
+	for _, directive := range robotsValueDirectives {
+		if name == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMetaRefresh parses a refresh content such as `5; url=/next`.
+func parseMetaRefresh(content string) (MetaRefresh, bool) {
+	content = strings.TrimSpace(content)
+	end := strings.IndexFunc(content, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end < 0 {
+		end = len(content)
+	}
+	delay, err := strconv.ParseFloat(content[:end], 64)
+	if err != nil {
+		return MetaRefresh{}, false
+	}
+	refresh := MetaRefresh{DelaySeconds: int(delay)}
+
+	target := strings.TrimLeft(content[end:], " \t\n\f\r;,")
+	if len(target) >= 3 && strings.EqualFold(target[:3], "url") {
+		if rest := strings.TrimLeft(target[3:], " \t\n\f\r"); strings.HasPrefix(rest, "=") {
+			target = strings.TrimLeft(rest[1:], " \t\n\f\r")
+		}
+	}
+	if len(target) > 0 && (target[0] == '"' || target[0] == '\'') {
+		if closing := strings.IndexByte(target[1:], target[0]); closing >= 0 {
+			target = target[1 : closing+1]
+		} else {
+			target = target[1:]
+		}
+	}
+	refresh.URL = strings.TrimSpace(target)
+	return refresh, true
+}
@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseSEO(t *testing.T, html string, xRobotsTag ...string) SEO {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	assert.NoError(t, err)
+	base, _ := url.Parse("https://example.com/docs/page")
+	return analyzeSEO(doc, base, xRobotsTag)
+}
+
+func TestAnalyzeSEO_ShouldExtractMetadata(t *testing.T) {
+	seo := parseSEO(t, `<!DOCTYPE html><html><head>
+		<title>
+			Getting started with the analyzer
+		</title>
+		<meta name="Description" content="Learn how to analyze a web page, read the report and fix the problems it finds.">
+		<meta name="robots" content="index, follow, max-snippet:50">
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<link rel="canonical" href="/docs/start">
+		<link rel="alternate" hreflang="de" href="https://example.de/docs/start">
+		<link rel="alternate" hreflang="x-default" href="/docs/start">
+		<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+		<link rel="Prev" href="intro">
+		<link rel="next" href="usage">
+	</head><body>
+		<svg><title>Logo</title></svg>
+	</body></html>`)
+
+	assert.Equal(t, SEO{
+		Title:             "Getting started with the analyzer",
+		TitleLength:       33,
+		Description:       "Learn how to analyze a web page, read the report and fix the problems it finds.",
+		DescriptionLength: 79,
+		Robots:            "index, follow, max-snippet:50",
+		Indexable:         true,
+		Followable:        true,
+		Canonical:         "https://example.com/docs/start",
+		Alternates: []HreflangAlternate{
+			{Hreflang: "de", URL: "https://example.de/docs/start"},
+			{Hreflang: "x-default", URL: "https://example.com/docs/start"},
+		},
+		Prev:     "https://example.com/docs/intro",
+		Next:     "https://example.com/docs/usage",
+		Viewport: "width=device-width, initial-scale=1",
+	}, seo)
+}
+
+func TestAnalyzeSEO_ShouldReportIssues(t *testing.T) {
+	seo := parseSEO(t, `<html><head>
+		<title>Home</title>
+		<title>Second</title>
+		<meta name="description" content="Home">
+		<meta name="description" content="Another description">
+		<meta http-equiv="Refresh" content="3; URL='/moved'">
+		<link rel="canonical" href="/a">
+		<link rel="canonical" href="/b">
+	</head><body></body></html>`, "googlebot: nofollow", "noindex")
+
+	assert.False(t, seo.Indexable)
+	assert.True(t, seo.Followable)
+	assert.Equal(t, []string{"googlebot: nofollow", "noindex"}, seo.XRobotsTag)
+	assert.Equal(t, &MetaRefresh{DelaySeconds: 3, URL: "https://example.com/moved"}, seo.Refresh)
+	assert.Equal(t, []Issue{
+		{Code: SEOIssueTitleTooShort, Message: "the title is 4 characters long; aim for 10 to 60"},
+		{Code: SEOIssueMultipleTitles, Message: "the head has 2 title elements"},
+		{Code: SEOIssueDescriptionTooShort, Message: "the meta description is 4 characters long; aim for 50 to 160"},
+		{Code: SEOIssueMultipleDescriptions, Message: "the page has 2 meta descriptions"},
+		{Code: SEOIssueDuplicateDescription, Message: "the meta description repeats the title"},
+		{Code: SEOIssueNotIndexable, Message: "search engines are asked not to index the page"},
+		{Code: SEOIssueMultipleCanonicals, Message: "the page has 2 canonical links"},
+		{Code: SEOIssueMetaRefresh, Message: "a meta refresh redirects to https://example.com/moved after 3 second(s)"},
+		{Code: SEOIssueMissingViewport, Message: "the page has no viewport meta tag"},
+	}, seo.Issues)
+}
+
+func TestAnalyzeSEO_ShouldReportMissingAndLongMetadata(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	seo := parseSEO(t, `<html><head>
+		<title>`+long+`</title>
+		<meta name="viewport" content="width=device-width">
+	</head><body><title>Not in the head</title></body></html>`)
+
+	assert.Equal(t, []Issue{
+		{Code: SEOIssueTitleTooLong, Message: "the title is 199 characters long and may be cut off after 60"},
+		{Code: SEOIssueMissingDescription, Message: "the page has no meta description"},
+	}, seo.Issues)
+}
+
+func TestAnalyze_ShouldReadXRobotsTagHeader(t *testing.T) {
+	pageUrl := "https://example.com/private"
+	d := newFixtureService(pageUrl, Fixture{
+		Header: http.Header{"X-Robots-Tag": {"noindex, nofollow"}},
+		Body:   "<html><head><title>Private page</title></head><body></body></html>",
+	})
+
+	result, err := d.Analyze(context.Background(), pageUrl, WithChecks(CheckSEO))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Private page", result.SEO.Title)
+	assert.Equal(t, []string{"noindex, nofollow"}, result.SEO.XRobotsTag)
+	assert.False(t, result.SEO.Indexable)
+	assert.False(t, result.SEO.Followable)
+}
+
+func TestRobotsDirectives(t *testing.T) {
+	tests := []struct {
+		value    string
+		noindex  bool
+		nofollow bool
+	}{
+		{value: "noindex", noindex: true},
+		{value: "NOINDEX, NOFOLLOW", noindex: true, nofollow: true},
+		{value: "none", noindex: true, nofollow: true},
+		{value: "all"},
+		{value: "max-snippet: 20, nofollow", nofollow: true},
+		{value: "unavailable_after: 2030-01-01"},
+		{value: "googlebot: noindex"},
+		{value: "nofollow, googlebot: noindex", nofollow: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			noindex, nofollow := robotsDirectives(tt.value)
+			assert.Equal(t, tt.noindex, noindex)
+			assert.Equal(t, tt.nofollow, nofollow)
+		})
+	}
+}
+
+func TestParseMetaRefresh(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected MetaRefresh
+		ok       bool
+	}{
+		{content: "0", expected: MetaRefresh{}, ok: true},
+		{content: "5;url=https://example.org/", expected: MetaRefresh{DelaySeconds: 5, URL: "https://example.org/"}, ok: true},
+		{content: " 10 , URL = \"next.html\" ", expected: MetaRefresh{DelaySeconds: 10, URL: "next.html"}, ok: true},
+		{content: "2.5; /elsewhere", expected: MetaRefresh{DelaySeconds: 2, URL: "/elsewhere"}, ok: true},
+		{content: "url=/missing-delay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			refresh, ok := parseMetaRefresh(tt.content)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, refresh)
+		})
+	}
+}
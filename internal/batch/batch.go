@@ -0,0 +1,146 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// DefaultConcurrency is the number of analyses a batch runs at once when
+// none is configured.
+const DefaultConcurrency = 4
+
+// MaxLineBytes bounds a single request line.
+const MaxLineBytes = 1 << 20
+
+// Result is the outcome of one input line. Line numbers start at 1 and let
+// callers match results, which arrive in completion order, to their input.
+type Result struct {
+	Line   int                   `json:"line"`
+	URL    string                `json:"url,omitempty"`
+	Result *PageAnalysisResponse `json:"result,omitempty"`
+	Error  string                `json:"error,omitempty"`
+	Code   string                `json:"code,omitempty"`
+}
+
+// Run reads one PageAnalysisRequest per line from input and analyzes them
+// with at most concurrency analyses in flight. Every non-blank line yields
+// exactly one Result passed to emit; emit is never called concurrently.
+// A line that cannot be parsed or analyzed produces a Result with Error set
+// and does not stop the batch. Run returns an error only when the input
+// cannot be read or ctx is done.
+func Run(ctx context.Context, service analyzer.Service, input io.Reader, concurrency int, emit func(Result)) error {
+	r := newRunner(ctx, service, concurrency, emit)
+	defer r.wait()
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var request PageAnalysisRequest
+		if err := json.Unmarshal([]byte(text), &request); err != nil {
+			r.send(Result{Line: line, Error: fmt.Sprintf("invalid request: %v", err), Code: analyzer.CodeInvalidInput})
+			continue
+		}
+		if err := r.submit(line, request); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("[ERROR] Failed to read batch input after line %d: %v", line, err)
+		return fmt.Errorf("failed to read batch input after line %d: %w", line, err)
+	}
+	return nil
+}
+
+// RunRequests is Run for requests that are already decoded; their Line is
+// their position in requests, starting at 1.
+func RunRequests(ctx context.Context, service analyzer.Service, requests []PageAnalysisRequest, concurrency int, emit func(Result)) error {
+	r := newRunner(ctx, service, concurrency, emit)
+	defer r.wait()
+
+	for i, request := range requests {
+		if err := r.submit(i+1, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type runner struct {
+	ctx     context.Context
+	service analyzer.Service
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	emitMu  sync.Mutex
+	emit    func(Result)
+}
+
+func newRunner(ctx context.Context, service analyzer.Service, concurrency int, emit func(Result)) *runner {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	return &runner{ctx: ctx, service: service, slots: make(chan struct{}, concurrency), emit: emit}
+}
+
+func (r *runner) send(result Result) {
+	r.emitMu.Lock()
+	defer r.emitMu.Unlock()
+	r.emit(result)
+}
+
+// submit starts the analysis of request once a slot is free. It only fails
+// when the context is done.
+func (r *runner) submit(line int, request PageAnalysisRequest) error {
+	if request.WebpageUrl == "" {
+		r.send(Result{Line: line, Error: "invalid request: missing webpageUrl", Code: analyzer.CodeInvalidInput})
+		return nil
+	}
+	select {
+	case r.slots <- struct{}{}:
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+	if r.ctx.Err() != nil {
+		<-r.slots
+		return r.ctx.Err()
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() { <-r.slots }()
+		r.send(analyze(r.ctx, r.service, line, request))
+	}()
+	return nil
+}
+
+func (r *runner) wait() {
+	r.wg.Wait()
+}
+
+func analyze(ctx context.Context, service analyzer.Service, line int, request PageAnalysisRequest) Result {
+	result := Result{Line: line, URL: request.WebpageUrl}
+	response, err := service.Analyze(ctx, request.WebpageUrl, analyzer.RequestOptions(request)...)
+	if err != nil {
+		log.Printf("[ERROR] Batch line %d: analysis failed for %s: %v", line, request.WebpageUrl, err)
+		result.Error = err.Error()
+		result.Code = analyzer.ErrorCode(err)
+		return result
+	}
+	result.Result = &response
+	return result
+}
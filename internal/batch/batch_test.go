@@ -0,0 +1,121 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockAnalyzerService struct {
+	AnalyzeFunc func(ctx context.Context, url string) (model.PageAnalysisResponse, error)
+}
+
+func (s MockAnalyzerService) Analyze(ctx context.Context, url string, options ...analyzer.Option) (model.PageAnalysisResponse, error) {
+	return s.AnalyzeFunc(ctx, url)
+}
+
+func TestRun_ReportsEveryLine(t *testing.T) {
+	service := MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			switch url {
+			case "https://broken.example.com":
+				return model.PageAnalysisResponse{}, &analyzer.Error{Code: analyzer.CodeDNSFailure, Message: "failed to fetch the webpage"}
+			case "http://169.254.169.254":
+				return model.PageAnalysisResponse{}, fmt.Errorf("failed to fetch the webpage: %w", &netguard.BlockedError{
+					Address: netip.MustParseAddr("169.254.169.254"),
+					Range:   netip.MustParsePrefix("169.254.0.0/16"),
+				})
+			}
+			return model.PageAnalysisResponse{URL: url, Title: "ok"}, nil
+		},
+	}
+	input := strings.Join([]string{
+		`{"webpageUrl":"https://example.com"}`,
+		``,
+		`{"webpageUrl":"https://broken.example.com"}`,
+		`not json`,
+		`{"checks":["title"]}`,
+		`{"webpageUrl":"http://169.254.169.254"}`,
+	}, "\n")
+
+	var results []Result
+	err := Run(context.Background(), service, strings.NewReader(input), 2, func(result Result) {
+		results = append(results, result)
+	})
+
+	assert.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+	assert.Len(t, results, 5)
+	assert.Equal(t, 1, results[0].Line)
+	assert.Equal(t, "ok", results[0].Result.Title)
+	assert.Equal(t, Result{Line: 3, URL: "https://broken.example.com", Error: "failed to fetch the webpage", Code: analyzer.CodeDNSFailure}, results[1])
+	assert.Equal(t, 4, results[2].Line)
+	assert.Contains(t, results[2].Error, "invalid request")
+	assert.Equal(t, analyzer.CodeInvalidInput, results[2].Code)
+	assert.Equal(t, Result{Line: 5, Error: "invalid request: missing webpageUrl", Code: analyzer.CodeInvalidInput}, results[3])
+	assert.Equal(t, netguard.Code, results[4].Code)
+}
+
+func TestRun_BoundsConcurrencyAndStreamsInCompletionOrder(t *testing.T) {
+	var running, peak atomic.Int32
+	service := MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			if url == "https://slow.example.com" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			return model.PageAnalysisResponse{URL: url}, nil
+		},
+	}
+	input := `{"webpageUrl":"https://slow.example.com"}` + "\n"
+	for i := 0; i < 6; i++ {
+		input += fmt.Sprintf(`{"webpageUrl":"https://example.com/%d"}`+"\n", i)
+	}
+
+	var lines []int
+	err := Run(context.Background(), service, strings.NewReader(input), 2, func(result Result) {
+		lines = append(lines, result.Line)
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, lines, 7)
+	assert.Equal(t, 1, lines[len(lines)-1])
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestRun_StopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	service := MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			cancel()
+			<-ctx.Done()
+			return model.PageAnalysisResponse{}, fmt.Errorf("failed to fetch the webpage: %w", ctx.Err())
+		},
+	}
+	input := strings.Repeat(`{"webpageUrl":"https://example.com"}`+"\n", 3)
+
+	var results []Result
+	err := Run(ctx, service, strings.NewReader(input), 1, func(result Result) {
+		results = append(results, result)
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, results, 1)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/batch"
+)
+
+// MaxBatchConcurrency caps the ?concurrency= of a batch request.
+const MaxBatchConcurrency = 16
+
+// BatchAnalysisHandler analyzes a JSONL body of analysis requests and
+// streams one JSON line per request back in completion order.
+func (webPageAnalyzer *WebPageAnalyzer) BatchAnalysisHandler(c *gin.Context) {
+	log.Println("[INFO] Received /analyzer/batch request")
+
+	concurrency := batch.DefaultConcurrency
+	if value := c.Query("concurrency"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > MaxBatchConcurrency {
+			log.Printf("[ERROR] Invalid batch concurrency: %s", value)
+			writeProblem(c, NewProblem(analyzer.CodeInvalidInput, "concurrency must be between 1 and "+strconv.Itoa(MaxBatchConcurrency)))
+			return
+		}
+		concurrency = parsed
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	count := 0
+	err := batch.Run(c.Request.Context(), webPageAnalyzer.Service, c.Request.Body, concurrency, func(result batch.Result) {
+		count++
+		if err := encoder.Encode(batchBody(c, result)); err != nil {
+			log.Printf("[ERROR] Failed to write batch result for line %d: %v", result.Line, err)
+			return
+		}
+		c.Writer.Flush()
+	})
+	if err != nil {
+		log.Printf("[ERROR] Batch stopped after %d results: %v", count, err)
+		encoder.Encode(gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("[INFO] Batch finished with %d results", count)
+}
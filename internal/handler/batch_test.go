@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/batch"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchAnalysisHandler_StreamsResults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/analyzer/batch?concurrency=1", strings.NewReader(
+		`{"webpageUrl":"https://example.com"}`+"\n"+`{"webpageUrl":"invalid-url"}`+"\n"))
+	mockService := MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			if url == "invalid-url" {
+				return model.PageAnalysisResponse{}, &analyzer.Error{Code: analyzer.CodeInvalidInput, Message: "invalid URL format"}
+			}
+			return model.PageAnalysisResponse{URL: url, Title: "Sample Title"}, nil
+		},
+	}
+
+	var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+	webPageAnalyzer.BatchAnalysisHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	var first, second batch.Result
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "Sample Title", first.Result.Title)
+	assert.Equal(t, batch.Result{Line: 2, URL: "invalid-url", Error: "invalid URL format", Code: analyzer.CodeInvalidInput}, second)
+}
+
+func TestBatchAnalysisHandler_InvalidConcurrency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/analyzer/batch?concurrency=100", strings.NewReader(""))
+
+	var webPageAnalyzer = WebPageAnalyzer{Service: MockAnalyzerService{}}
+	webPageAnalyzer.BatchAnalysisHandler(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "concurrency must be between 1 and 16", decodeProblem(t, w.Body).Detail)
+}
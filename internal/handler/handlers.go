@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
 	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/render"
 )
 
 type WebPageAnalyzer struct {
@@ -20,22 +21,54 @@ func (webPageAnalyzer *WebPageAnalyzer) WebPageAnalyzerHandler(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		log.Printf("[ERROR] Invalid request format or missing webpageUrl: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request format or missing webpageUrl",
-		})
+		writeProblem(c, NewProblem(analyzer.CodeInvalidInput, "Invalid request format or missing webpageUrl"))
 		return
 	}
-	response, err := webPageAnalyzer.Service.Analyze(request.WebpageUrl)
+	format, ok := negotiateFormat(c)
+	if !ok {
+		return
+	}
+
+	response, err := webPageAnalyzer.Service.Analyze(c.Request.Context(), request.WebpageUrl, analyzer.RequestOptions(request)...)
 	if err != nil {
 		log.Printf("[ERROR] Analysis failed for %s: %v", request.WebpageUrl, err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		writeProblem(c, ProblemForError(err))
 		return
 	}
 	log.Printf("[INFO] Analysis successful for %s", request.WebpageUrl)
-	c.JSON(http.StatusOK, gin.H{
-		"url":     request.WebpageUrl,
-		"content": response,
+	if isLegacy(c) && writeLegacyResult(c, format, request.WebpageUrl, response) {
+		return
+	}
+	if format != render.FormatJSON {
+		c.Header("Content-Type", render.ContentTypes[format])
+		c.Status(http.StatusOK)
+		analyses := []render.Analysis{{URL: request.WebpageUrl, Result: &response}}
+		if err := render.Write(c.Writer, format, analyses); err != nil {
+			log.Printf("[ERROR] Failed to render %s result for %s: %v", format, request.WebpageUrl, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, AnalyzerResponse{
+		URL:     request.WebpageUrl,
+		Content: response,
 	})
 }
+
+// negotiateFormat picks the response format from ?format= or, failing that,
+// the Accept header. It answers the request itself when neither is usable.
+func negotiateFormat(c *gin.Context) (string, bool) {
+	if name := c.Query("format"); name != "" {
+		format, ok := render.FormatForName(name)
+		if !ok {
+			log.Printf("[ERROR] Unknown format: %s", name)
+			writeProblem(c, NewProblem(analyzer.CodeInvalidInput, "unknown format: "+name))
+		}
+		return format, ok
+	}
+	format, ok := render.FormatForAccept(c.GetHeader("Accept"))
+	if !ok {
+		log.Printf("[ERROR] Unsupported Accept header: %s", c.GetHeader("Accept"))
+		writeProblem(c, NewProblem(CodeNotAcceptable, "unsupported Accept header"))
+	}
+	return format, ok
+}
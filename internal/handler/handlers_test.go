@@ -2,13 +2,17 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
 	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/netguard"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 )
 
@@ -30,8 +34,8 @@ func TestWebPageAnalyzerHandler_InvalidJSON(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 
-	resp := decodeJSONResponse(t, w.Body)
-	assert.Equal(t, "Invalid request format or missing webpageUrl", resp["error"])
+	resp := decodeProblem(t, w.Body)
+	assert.Equal(t, "Invalid request format or missing webpageUrl", resp.Detail)
 }
 
 func TestWebPageAnalyzerHandler_InvalidWebPageURL(t *testing.T) {
@@ -44,16 +48,20 @@ func TestWebPageAnalyzerHandler_InvalidWebPageURL(t *testing.T) {
 	c.Request = req
 	mockService := MockAnalyzerService{
 		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
-			return model.PageAnalysisResponse{}, fmt.Errorf("invalid URL format")
+			return model.PageAnalysisResponse{}, &analyzer.Error{Code: analyzer.CodeInvalidInput, Message: "invalid URL format"}
 		},
 	}
 	var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
 	webPageAnalyzer.WebPageAnalyzerHandler(c)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, model.ProblemContentType, w.Header().Get("Content-Type"))
 
-	resp := decodeJSONResponse(t, w.Body)
-	assert.Equal(t, "invalid URL format", resp["error"])
+	resp := decodeProblem(t, w.Body)
+	assert.Equal(t, "invalid URL format", resp.Detail)
+	assert.Equal(t, analyzer.CodeInvalidInput, resp.Code)
+	assert.Equal(t, ProblemTypePrefix+analyzer.CodeInvalidInput, resp.Type)
+	assert.Equal(t, http.StatusBadRequest, resp.Status)
 }
 
 func TestWebPageAnalyzerHandler_validJSON(t *testing.T) {
@@ -82,6 +90,184 @@ func TestWebPageAnalyzerHandler_validJSON(t *testing.T) {
 	assert.Equal(t, "https://example.com", resp.URL)
 }
 
+func TestWebPageAnalyzerHandler_BlockedTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req := newTestRequest(`{"webpageUrl":"http://169.254.169.254/" }`)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	mockService := MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			blocked := &netguard.BlockedError{
+				Address: netip.MustParseAddr("169.254.169.254"),
+				Range:   netip.MustParsePrefix("169.254.0.0/16"),
+			}
+			return model.PageAnalysisResponse{}, fmt.Errorf("failed to fetch the webpage: %w", blocked)
+		},
+	}
+	var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+	webPageAnalyzer.WebPageAnalyzerHandler(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	resp := decodeProblem(t, w.Body)
+	assert.Equal(t, "blocked_target", resp.Code)
+	assert.Equal(t, "failed to fetch the webpage: target address 169.254.169.254 is not allowed (169.254.0.0/16)", resp.Detail)
+}
+
+func TestWebPageAnalyzerHandler_SARIF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req := newTestRequest(`{"webpageUrl":"https://example.com" }`)
+	req.Header.Set("Accept", "application/sarif+json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	mockService := MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{URL: url, HeadingCounts: map[string]int{"h1": 1}}, nil
+		},
+	}
+	var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+	webPageAnalyzer.WebPageAnalyzerHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/sarif+json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"version": "2.1.0"`)
+	assert.Contains(t, w.Body.String(), `"ruleId": "missing-title"`)
+}
+
+func TestWebPageAnalyzerHandler_FormatQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		format      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"md", http.StatusOK, "text/markdown; charset=utf-8", "| Title | Sample Title |"},
+		{"yaml", http.StatusOK, "application/yaml", "  title: Sample Title\n"},
+		{"csv", http.StatusOK, "text/csv; charset=utf-8", "https://example.com,,,,Sample Title,"},
+		{"html", http.StatusOK, "text/html; charset=utf-8", "<tr><th>Title</th><td>Sample Title</td></tr>"},
+		{"json", http.StatusOK, "application/json; charset=utf-8", `"title":"Sample Title"`},
+		{"pdf", http.StatusBadRequest, model.ProblemContentType, `"detail":"unknown format: pdf"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			req := newTestRequest(`{"webpageUrl":"https://example.com" }`)
+			req.URL.RawQuery = "format=" + tt.format
+			req.Header.Set("Accept", "application/sarif+json")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			mockService := MockAnalyzerService{
+				AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+					return model.PageAnalysisResponse{URL: url, Title: "Sample Title"}, nil
+				},
+			}
+			var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+			webPageAnalyzer.WebPageAnalyzerHandler(c)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), tt.body)
+		})
+	}
+}
+
+func TestWebPageAnalyzerHandler_ErrorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"invalid input", &analyzer.Error{Code: analyzer.CodeInvalidInput, Message: "invalid URL format"}, http.StatusBadRequest, analyzer.CodeInvalidInput},
+		{"dns failure", &analyzer.Error{Code: analyzer.CodeDNSFailure, Message: "could not resolve"}, http.StatusBadGateway, analyzer.CodeDNSFailure},
+		{"upstream status", &analyzer.Error{Code: analyzer.CodeUpstreamStatus, Message: "status code: 500", UpstreamStatus: 500}, http.StatusBadGateway, analyzer.CodeUpstreamStatus},
+		{"timeout", fmt.Errorf("failed to fetch the webpage: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, analyzer.CodeTimeout},
+		{"canceled", fmt.Errorf("failed to fetch the webpage: %w", context.Canceled), StatusClientClosedRequest, analyzer.CodeCanceled},
+		{"non html", &analyzer.Error{Code: analyzer.CodeNonHTMLContent, Message: "not HTML"}, http.StatusUnprocessableEntity, analyzer.CodeNonHTMLContent},
+		{"too large", &analyzer.Error{Code: analyzer.CodeBodyTooLarge, Message: "too large"}, http.StatusUnprocessableEntity, analyzer.CodeBodyTooLarge},
+		{"unclassified", fmt.Errorf("boom"), http.StatusInternalServerError, analyzer.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = newTestRequest(`{"webpageUrl":"https://example.com" }`)
+			mockService := MockAnalyzerService{
+				AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+					return model.PageAnalysisResponse{}, tt.err
+				},
+			}
+			var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+			webPageAnalyzer.WebPageAnalyzerHandler(c)
+
+			assert.Equal(t, tt.status, w.Code)
+			problem := decodeProblem(t, w.Body)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.err.Error(), problem.Detail)
+			assert.NotEmpty(t, problem.Title)
+		})
+	}
+}
+
+func TestWebPageAnalyzerHandler_ProblemCarriesRedirects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	chain := &model.RedirectChain{
+		Hops: []model.RedirectHop{
+			{URL: "https://example.com/a", StatusCode: http.StatusFound, Location: "https://example.com/b"},
+			{URL: "https://example.com/b", StatusCode: http.StatusFound, Location: "https://example.com/a"},
+		},
+		HopCount: 2,
+		FinalURL: "https://example.com/a",
+		Loop:     true,
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newTestRequest(`{"webpageUrl":"https://example.com/a" }`)
+	mockService := MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{URL: url, Redirects: chain}, &analyzer.Error{
+				Code:      analyzer.CodeFetchFailed,
+				Message:   "failed to fetch the webpage: redirect loop",
+				Redirects: chain,
+			}
+		},
+	}
+	var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+	webPageAnalyzer.WebPageAnalyzerHandler(c)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Equal(t, chain, decodeProblem(t, w.Body).Redirects)
+}
+
+func TestWebPageAnalyzerHandler_NotAcceptable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req := newTestRequest(`{"webpageUrl":"https://example.com" }`)
+	req.Header.Set("Accept", "image/png")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	var webPageAnalyzer = WebPageAnalyzer{Service: MockAnalyzerService{}}
+	webPageAnalyzer.WebPageAnalyzerHandler(c)
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, "unsupported Accept header", decodeProblem(t, w.Body).Detail)
+}
+
 func decodePageAnalysisResponse(t *testing.T, body *bytes.Buffer) model.PageAnalysisResponse {
 	t.Helper()
 	var data model.PageAnalysisResponse
@@ -92,9 +278,9 @@ func decodePageAnalysisResponse(t *testing.T, body *bytes.Buffer) model.PageAnal
 	return data
 }
 
-func decodeJSONResponse(t *testing.T, body *bytes.Buffer) map[string]string {
+func decodeProblem(t *testing.T, body *bytes.Buffer) model.Problem {
 	t.Helper()
-	var data map[string]string
+	var data model.Problem
 	err := json.Unmarshal(body.Bytes(), &data)
 	if err != nil {
 		t.Fatalf("Failed to decode JSON response: %v", err)
@@ -109,10 +295,14 @@ func newTestRequest(jsonBody string) *http.Request {
 }
 
 type MockAnalyzerService struct {
-	AnalyzeFunc func(url string) (model.PageAnalysisResponse, error)
+	AnalyzeFunc        func(url string) (model.PageAnalysisResponse, error)
+	AnalyzeContextFunc func(ctx context.Context, url string) (model.PageAnalysisResponse, error)
 }
 
-func (s MockAnalyzerService) Analyze(url string) (model.PageAnalysisResponse, error) {
+func (s MockAnalyzerService) Analyze(ctx context.Context, url string, options ...analyzer.Option) (model.PageAnalysisResponse, error) {
+	if s.AnalyzeContextFunc != nil {
+		return s.AnalyzeContextFunc(ctx, url)
+	}
 	if s.AnalyzeFunc != nil {
 		return s.AnalyzeFunc(url)
 	}
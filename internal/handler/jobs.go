@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/jobs"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+type AnalysisJobs struct {
+	Queue *jobs.Queue
+}
+
+func (analysisJobs *AnalysisJobs) CreateAnalysisHandler(c *gin.Context) {
+	var request PageAnalysisRequest
+
+	log.Printf("[INFO] Received POST %s request", c.Request.URL.Path)
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("[ERROR] Invalid request format or missing webpageUrl: %v", err)
+		writeProblem(c, NewProblem(analyzer.CodeInvalidInput, "Invalid request format or missing webpageUrl"))
+		return
+	}
+
+	job, err := analysisJobs.Queue.Submit(request.WebpageUrl, analyzer.RequestOptions(request)...)
+	if err != nil {
+		log.Printf("[ERROR] Could not queue analysis of %s: %v", request.WebpageUrl, err)
+		writeProblem(c, NewProblem(CodeUnavailable, err.Error()))
+		return
+	}
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+job.ID)
+	c.JSON(http.StatusAccepted, jobBody(c, job))
+}
+
+func (analysisJobs *AnalysisJobs) GetAnalysisHandler(c *gin.Context) {
+	job, err := analysisJobs.Queue.Get(c.Param("id"))
+	if err != nil {
+		writeProblem(c, NewProblem(CodeNotFound, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, jobBody(c, job))
+}
+
+func (analysisJobs *AnalysisJobs) CancelAnalysisHandler(c *gin.Context) {
+	job, err := analysisJobs.Queue.Cancel(c.Param("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		writeProblem(c, NewProblem(CodeNotFound, err.Error()))
+	case errors.Is(err, jobs.ErrFinished):
+		problem := NewProblem(CodeConflict, err.Error())
+		problem.Job = jobBody(c, job)
+		writeProblem(c, problem)
+	default:
+		c.JSON(http.StatusOK, jobBody(c, job))
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/jobs"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newJobsRouter(service MockAnalyzerService) (*gin.Engine, *jobs.Queue) {
+	gin.SetMode(gin.TestMode)
+	queue := jobs.NewQueue(service, jobs.Config{Workers: 1})
+	a := AnalysisJobs{Queue: queue}
+	r := gin.New()
+	r.POST("/analyses", a.CreateAnalysisHandler)
+	r.GET("/analyses/:id", a.GetAnalysisHandler)
+	r.DELETE("/analyses/:id", a.CancelAnalysisHandler)
+	return r, queue
+}
+
+func serve(r *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	req := newTestRequest(body)
+	req.Method = method
+	req.URL.Path = path
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeJob(t *testing.T, w *httptest.ResponseRecorder) jobs.Job {
+	t.Helper()
+	var job jobs.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	return job
+}
+
+func TestAnalysisJobs_CreateAndGet(t *testing.T) {
+	r, queue := newJobsRouter(MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{URL: url, Title: "Sample Title"}, nil
+		},
+	})
+	defer queue.Close()
+
+	w := serve(r, http.MethodPost, "/analyses", `{"webpageUrl":"https://example.com"}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	created := decodeJob(t, w)
+	assert.Equal(t, "/analyses/"+created.ID, w.Header().Get("Location"))
+	assert.Equal(t, "https://example.com", created.URL)
+
+	assert.Eventually(t, func() bool {
+		return decodeJob(t, serve(r, http.MethodGet, "/analyses/"+created.ID, "")).Status == jobs.StatusSucceeded
+	}, time.Second, time.Millisecond)
+
+	job := decodeJob(t, serve(r, http.MethodGet, "/analyses/"+created.ID, ""))
+	assert.Equal(t, "Sample Title", job.Result.Title)
+}
+
+func TestAnalysisJobs_CreateInvalidJSON(t *testing.T) {
+	r, queue := newJobsRouter(MockAnalyzerService{})
+	defer queue.Close()
+
+	w := serve(r, http.MethodPost, "/analyses", `{"webpageUrl": }`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid request format or missing webpageUrl", decodeProblem(t, w.Body).Detail)
+}
+
+func TestAnalysisJobs_GetUnknown(t *testing.T) {
+	r, queue := newJobsRouter(MockAnalyzerService{})
+	defer queue.Close()
+
+	w := serve(r, http.MethodGet, "/analyses/missing", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "analysis job not found", decodeProblem(t, w.Body).Detail)
+}
+
+func TestAnalysisJobs_Cancel(t *testing.T) {
+	r, queue := newJobsRouter(MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{URL: url}, nil
+		},
+	})
+	defer queue.Close()
+
+	created := decodeJob(t, serve(r, http.MethodPost, "/analyses", `{"webpageUrl":"https://example.com"}`))
+	assert.Eventually(t, func() bool {
+		job, _ := queue.Get(created.ID)
+		return job.Finished()
+	}, time.Second, time.Millisecond)
+
+	w := serve(r, http.MethodDelete, "/analyses/"+created.ID, "")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var problem struct {
+		Code string   `json:"code"`
+		Job  jobs.Job `json:"job"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeConflict, problem.Code)
+	assert.Equal(t, created.ID, problem.Job.ID)
+	assert.Equal(t, jobs.StatusSucceeded, problem.Job.Status)
+}
+
+func TestAnalysisJobs_CancelRunning(t *testing.T) {
+	started := make(chan struct{})
+	r, queue := newJobsRouter(MockAnalyzerService{
+		AnalyzeContextFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			close(started)
+			<-ctx.Done()
+			return model.PageAnalysisResponse{URL: url, TimedOut: true}, nil
+		},
+	})
+	defer queue.Close()
+
+	created := decodeJob(t, serve(r, http.MethodPost, "/analyses", `{"webpageUrl":"https://example.com"}`))
+	<-started
+
+	w := serve(r, http.MethodDelete, "/analyses/"+created.ID, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, jobs.StatusCancelled, decodeJob(t, w).Status)
+}
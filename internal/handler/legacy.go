@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/batch"
+	"github.com/naskavinda/webpageanalyzer/internal/jobs"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/render"
+)
+
+// legacyKey marks a request served on a deprecated unversioned path.
+const legacyKey = "legacy"
+
+func isLegacy(c *gin.Context) bool {
+	return c.GetBool(legacyKey)
+}
+
+// legacyPageAnalysisResponse is the result as the unversioned paths encoded
+// it before /v1: Go field names and none of the sections added since.
+type legacyPageAnalysisResponse struct {
+	URL                       string
+	FinalURL                  string
+	Redirects                 *legacyRedirectChain `json:",omitempty"`
+	HTMLVersion               string
+	Title                     string
+	HeadingCounts             map[string]int
+	InternalLinks             int
+	ExternalLinks             int
+	InaccessibleLinks         int
+	InaccessibleInternalLinks int
+	InaccessibleExternalLinks int
+	LinkStatusCounts          map[string]int
+	HasLoginForm              bool
+	LinkCheckLimits           LinkCheckLimits
+	Links                     []legacyLinkDetail `json:",omitempty"`
+	TimedOut                  bool
+	Warnings                  []legacyIssue  `json:",omitempty"`
+	Sections                  map[string]any `json:",omitempty"`
+	Policy                    *PolicyReport  `json:",omitempty"`
+}
+
+type legacyLinkDetail struct {
+	URL            string
+	Href           string
+	Text           string
+	Rel            string `json:",omitempty"`
+	Target         string `json:",omitempty"`
+	Classification string
+	Status         string
+	Checked        bool
+	Method         string               `json:",omitempty"`
+	Attempts       int                  `json:",omitempty"`
+	StatusCode     int                  `json:",omitempty"`
+	LatencyMs      int64                `json:",omitempty"`
+	Redirects      *legacyRedirectChain `json:",omitempty"`
+	ErrorKind      string               `json:",omitempty"`
+	Line           int                  `json:",omitempty"`
+	Column         int                  `json:",omitempty"`
+}
+
+type legacyRedirectChain struct {
+	Hops           []legacyRedirectHop
+	HopCount       int
+	FinalURL       string
+	Loop           bool
+	TooManyHops    bool
+	HTTPSDowngrade bool
+}
+
+type legacyRedirectHop struct {
+	URL        string
+	StatusCode int
+	Location   string
+}
+
+type legacyIssue struct {
+	Code    string
+	Message string
+}
+
+func legacyResponse(result *PageAnalysisResponse) *legacyPageAnalysisResponse {
+	if result == nil {
+		return nil
+	}
+	legacy := &legacyPageAnalysisResponse{
+		URL:                       result.URL,
+		FinalURL:                  result.FinalURL,
+		Redirects:                 legacyRedirects(result.Redirects),
+		HTMLVersion:               result.HTMLVersion,
+		Title:                     result.Title,
+		HeadingCounts:             result.HeadingCounts,
+		InternalLinks:             result.InternalLinks,
+		ExternalLinks:             result.ExternalLinks,
+		InaccessibleLinks:         result.InaccessibleLinks,
+		InaccessibleInternalLinks: result.InaccessibleInternalLinks,
+		InaccessibleExternalLinks: result.InaccessibleExternalLinks,
+		LinkStatusCounts:          result.LinkStatusCounts,
+		HasLoginForm:              result.HasLoginForm,
+		LinkCheckLimits:           result.LinkCheckLimits,
+		TimedOut:                  result.TimedOut,
+		Sections:                  result.Sections,
+		Policy:                    result.Policy,
+	}
+	for i := range result.Links {
+		legacy.Links = append(legacy.Links, *legacyLink(&result.Links[i]))
+	}
+	for _, warning := range result.Warnings {
+		legacy.Warnings = append(legacy.Warnings, legacyIssue{Code: warning.Code, Message: warning.Message})
+	}
+	return legacy
+}
+
+func legacyLink(link *LinkDetail) *legacyLinkDetail {
+	if link == nil {
+		return nil
+	}
+	return &legacyLinkDetail{
+		URL:            link.URL,
+		Href:           link.Href,
+		Text:           link.Text,
+		Rel:            link.Rel,
+		Target:         link.Target,
+		Classification: link.Classification,
+		Status:         link.Status,
+		Checked:        link.Checked,
+		Method:         link.Method,
+		Attempts:       link.Attempts,
+		StatusCode:     link.StatusCode,
+		LatencyMs:      link.LatencyMs,
+		Redirects:      legacyRedirects(link.Redirects),
+		ErrorKind:      link.ErrorKind,
+		Line:           link.Line,
+		Column:         link.Column,
+	}
+}
+
+func legacyRedirects(chain *RedirectChain) *legacyRedirectChain {
+	if chain == nil {
+		return nil
+	}
+	legacy := &legacyRedirectChain{
+		HopCount:       chain.HopCount,
+		FinalURL:       chain.FinalURL,
+		Loop:           chain.Loop,
+		TooManyHops:    chain.TooManyHops,
+		HTTPSDowngrade: chain.HTTPSDowngrade,
+	}
+	for _, hop := range chain.Hops {
+		legacy.Hops = append(legacy.Hops, legacyRedirectHop(hop))
+	}
+	return legacy
+}
+
+// writeLegacyResult answers with the encoding of the result from before /v1
+// when format exposes field names, and reports whether it did.
+func writeLegacyResult(c *gin.Context, format string, url string, response PageAnalysisResponse) bool {
+	body := gin.H{"url": url, "content": legacyResponse(&response)}
+	switch format {
+	case render.FormatJSON:
+		c.JSON(http.StatusOK, body)
+	case render.FormatYAML:
+		c.Header("Content-Type", render.ContentTypes[format])
+		c.Status(http.StatusOK)
+		if err := render.EncodeYAML(c.Writer, body); err != nil {
+			log.Printf("[ERROR] Failed to render %s result for %s: %v", format, url, err)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// legacyError is the error body of the unversioned paths, which predate
+// problem+json.
+func legacyError(problem Problem) gin.H {
+	body := gin.H{"error": problem.Detail, "code": problem.Code}
+	if problem.Job != nil {
+		body["job"] = problem.Job
+	}
+	return body
+}
+
+// legacyStatus is the status the unversioned paths answered problem with:
+// failed analyses were a 400, unless the target was blocked.
+func legacyStatus(problem Problem) int {
+	switch problem.Code {
+	case CodeNotAcceptable, CodeNotFound, CodeConflict, CodeUnavailable, analyzer.CodeBlockedTarget:
+		return problem.Status
+	}
+	return http.StatusBadRequest
+}
+
+// The legacy job and event shadow the fields that carry a result, so the
+// rest of the body keeps its encoding.
+
+type legacyJob struct {
+	jobs.Job
+	Result *legacyPageAnalysisResponse `json:"result,omitempty"`
+}
+
+type legacyBatchResult struct {
+	Line   int                         `json:"line"`
+	URL    string                      `json:"url,omitempty"`
+	Result *legacyPageAnalysisResponse `json:"result,omitempty"`
+	Error  string                      `json:"error,omitempty"`
+	Code   string                      `json:"code,omitempty"`
+}
+
+type legacyProgressEvent struct {
+	analyzer.ProgressEvent
+	Link   *legacyLinkDetail           `json:"link,omitempty"`
+	Result *legacyPageAnalysisResponse `json:"result,omitempty"`
+}
+
+// jobBody returns the body that describes job on the path of c.
+func jobBody(c *gin.Context, job jobs.Job) any {
+	if !isLegacy(c) {
+		return job
+	}
+	return legacyJob{Job: job, Result: legacyResponse(job.Result)}
+}
+
+func batchBody(c *gin.Context, result batch.Result) any {
+	if !isLegacy(c) {
+		return result
+	}
+	return legacyBatchResult{
+		Line:   result.Line,
+		URL:    result.URL,
+		Result: legacyResponse(result.Result),
+		Error:  result.Error,
+		Code:   result.Code,
+	}
+}
+
+func eventBody(c *gin.Context, event analyzer.ProgressEvent) any {
+	if !isLegacy(c) {
+		return event
+	}
+	return legacyProgressEvent{ProgressEvent: event, Link: legacyLink(event.Link), Result: legacyResponse(event.Result)}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// Error codes of the API itself; analysis failures use the analyzer codes.
+const (
+	CodeNotAcceptable = "not_acceptable"
+	CodeNotFound      = "not_found"
+	CodeConflict      = "conflict"
+	CodeUnavailable   = "unavailable"
+)
+
+// StatusClientClosedRequest is the non-standard status of an analysis whose
+// client went away before it finished.
+const StatusClientClosedRequest = 499
+
+// ProblemTypePrefix prefixes the code of a problem to form its type URI.
+const ProblemTypePrefix = "urn:webpageanalyzer:problem:"
+
+type problemType struct {
+	status int
+	title  string
+}
+
+var problemTypes = map[string]problemType{
+	analyzer.CodeInvalidInput:   {http.StatusBadRequest, "Invalid input"},
+	analyzer.CodeBlockedTarget:  {http.StatusForbidden, "Target not allowed"},
+	analyzer.CodeDNSFailure:     {http.StatusBadGateway, "Host could not be resolved"},
+	analyzer.CodeFetchFailed:    {http.StatusBadGateway, "Page could not be fetched"},
+	analyzer.CodeUpstreamStatus: {http.StatusBadGateway, "Page answered with an error status"},
+	analyzer.CodeTimeout:        {http.StatusGatewayTimeout, "Analysis timed out"},
+	analyzer.CodeCanceled:       {StatusClientClosedRequest, "Analysis canceled"},
+	analyzer.CodeNonHTMLContent: {http.StatusUnprocessableEntity, "Page is not HTML"},
+	analyzer.CodeBodyTooLarge:   {http.StatusUnprocessableEntity, "Page is too large"},
+	analyzer.CodeInternal:       {http.StatusInternalServerError, "Internal error"},
+	CodeNotAcceptable:           {http.StatusNotAcceptable, "Format not acceptable"},
+	CodeNotFound:                {http.StatusNotFound, "Not found"},
+	CodeConflict:                {http.StatusConflict, "Conflict"},
+	CodeUnavailable:             {http.StatusServiceUnavailable, "Service unavailable"},
+}
+
+// NewProblem builds the problem details for code.
+func NewProblem(code string, detail string) Problem {
+	kind, ok := problemTypes[code]
+	if !ok {
+		code, kind = analyzer.CodeInternal, problemTypes[analyzer.CodeInternal]
+	}
+	return Problem{
+		Type:   ProblemTypePrefix + code,
+		Title:  kind.title,
+		Status: kind.status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// ProblemForError builds the problem details of a failed analysis.
+func ProblemForError(err error) Problem {
+	problem := NewProblem(analyzer.ErrorCode(err), err.Error())
+	var analysisErr *analyzer.Error
+	if errors.As(err, &analysisErr) {
+		problem.UpstreamStatus = analysisErr.UpstreamStatus
+		problem.Redirects = analysisErr.Redirects
+	}
+	return problem
+}
+
+// writeProblem answers with problem, or with the legacy error body on the
+// deprecated unversioned paths.
+func writeProblem(c *gin.Context, problem Problem) {
+	problem.Instance = c.Request.URL.Path
+	if isLegacy(c) {
+		c.JSON(legacyStatus(problem), legacyError(problem))
+		return
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(problem.Status, problem)
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// RegisterRoutes registers the analysis endpoints on routes, which is the
+// /v1 group or, for the deprecated unversioned paths, the root.
+func RegisterRoutes(routes gin.IRoutes, w WebPageAnalyzer, a AnalysisJobs) {
+	routes.POST("/analyzer", w.WebPageAnalyzerHandler)
+	routes.GET("/analyzer/stream", w.StreamAnalysisHandler)
+	routes.POST("/analyzer/stream", w.StreamAnalysisHandler)
+	routes.POST("/analyzer/batch", w.BatchAnalysisHandler)
+	routes.POST("/analyses", a.CreateAnalysisHandler)
+	routes.GET("/analyses/:id", a.GetAnalysisHandler)
+	routes.DELETE("/analyses/:id", a.CancelAnalysisHandler)
+}
+
+// Deprecated marks responses of the unversioned paths as deprecated and
+// links them to their /v1 successor. Their bodies keep the encoding they had
+// before /v1.
+func Deprecated() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(legacyKey, true)
+		c.Header("Deprecation", "true")
+		c.Header("Link", "</"+APIVersion+c.Request.URL.Path+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/jobs"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/naskavinda/webpageanalyzer/internal/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVersionedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	service := MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{URL: url, Title: "Versioned"}, nil
+		},
+	}
+	queue := jobs.NewQueue(service, jobs.Config{Workers: 1})
+	w := WebPageAnalyzer{Service: service}
+	a := AnalysisJobs{Queue: queue}
+	r := gin.New()
+	v1 := r.Group("/" + model.APIVersion)
+	RegisterRoutes(v1, w, a)
+	v1.GET("/schema", SchemaHandler)
+	RegisterRoutes(r.Group("", Deprecated()), w, a)
+	return r
+}
+
+func TestRoutes_V1(t *testing.T) {
+	r := newVersionedRouter()
+
+	w := serve(r, http.MethodPost, "/v1/analyzer", `{"webpageUrl":"https://example.com"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	var response model.AnalyzerResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "https://example.com", response.URL)
+	assert.Equal(t, "Versioned", response.Content.Title)
+
+	w = serve(r, http.MethodPost, "/v1/analyses", `{"webpageUrl":"https://example.com"}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Regexp(t, `^/v1/analyses/.+`, w.Header().Get("Location"))
+}
+
+func TestRoutes_UnversionedAreDeprecated(t *testing.T) {
+	r := newVersionedRouter()
+
+	w := serve(r, http.MethodPost, "/analyzer", `{"webpageUrl":"https://example.com"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</v1/analyzer>; rel="successor-version"`, w.Header().Get("Link"))
+	assert.Contains(t, w.Body.String(), `"Title":"Versioned"`)
+
+	req := newTestRequest(`{"webpageUrl":"https://example.com"}`)
+	req.URL.Path = "/analyzer"
+	req.Header.Set("Accept", "application/yaml")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "  Title: Versioned\n")
+}
+
+func TestRoutes_UnversionedKeepLegacyErrors(t *testing.T) {
+	r := newVersionedRouter()
+
+	w := serve(r, http.MethodPost, "/analyzer", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"Invalid request format or missing webpageUrl","code":"invalid_input"}`, w.Body.String())
+
+	w = serve(r, http.MethodGet, "/analyses/unknown", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"analysis job not found","code":"not_found"}`, w.Body.String())
+
+	w = serve(r, http.MethodPost, "/v1/analyzer", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, model.ProblemContentType, w.Header().Get("Content-Type"))
+}
+
+func TestLegacyStatus(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		{code: analyzer.CodeUpstreamStatus, expected: http.StatusBadRequest},
+		{code: analyzer.CodeTimeout, expected: http.StatusBadRequest},
+		{code: analyzer.CodeBlockedTarget, expected: http.StatusForbidden},
+		{code: CodeConflict, expected: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.expected, legacyStatus(NewProblem(tt.code, "")))
+		})
+	}
+}
+
+func TestLegacyJobBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	job := jobs.Job{ID: "1", Status: jobs.StatusSucceeded, Result: &model.PageAnalysisResponse{
+		Title:     "Legacy",
+		Redirects: &model.RedirectChain{Hops: []model.RedirectHop{{URL: "http://example.com", StatusCode: 301}}, HopCount: 1},
+	}}
+	c.Set(legacyKey, true)
+
+	data, err := json.Marshal(jobBody(c, job))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"result":{"URL":"","FinalURL":"","Redirects":{"Hops":[{"URL":"http://example.com","StatusCode":301,"Location":""}],"HopCount":1`)
+	assert.Contains(t, string(data), `"Title":"Legacy"`)
+	assert.Contains(t, string(data), `"id":"1","status":"succeeded"`)
+
+	c.Set(legacyKey, false)
+	data, err = json.Marshal(jobBody(c, job))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"title":"Legacy"`)
+}
+
+func TestSchemaHandler(t *testing.T) {
+	r := newVersionedRouter()
+
+	w := serve(r, http.MethodGet, "/v1/schema", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var served map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &served))
+	assert.Equal(t, schema.ResponseID, served["$id"])
+	assert.Contains(t, served["$defs"], "PageAnalysisResponse")
+}
@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/schema"
+)
+
+// SchemaHandler serves the JSON Schema of the analysis response.
+func SchemaHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, schema.Response())
+}
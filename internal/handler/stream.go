@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+type serverSentEvent struct {
+	name string
+	data string
+}
+
+// StreamAnalysisHandler runs an analysis and streams its progress as
+// Server-Sent Events, ending with a done or error event. POST takes the
+// same body as /analyzer; GET takes ?webpageUrl= so browsers can use
+// EventSource.
+func (webPageAnalyzer *WebPageAnalyzer) StreamAnalysisHandler(c *gin.Context) {
+	var request PageAnalysisRequest
+
+	log.Println("[INFO] Received /analyzer/stream request")
+
+	var err error
+	if c.Request.Method == http.MethodGet {
+		request.WebpageUrl = c.Query("webpageUrl")
+		if request.WebpageUrl == "" {
+			err = errors.New("missing webpageUrl query parameter")
+		}
+	} else {
+		err = c.ShouldBindJSON(&request)
+	}
+	if err != nil {
+		log.Printf("[ERROR] Invalid request format or missing webpageUrl: %v", err)
+		writeProblem(c, NewProblem(analyzer.CodeInvalidInput, "Invalid request format or missing webpageUrl"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	events := make(chan serverSentEvent)
+	finished := make(chan error, 1)
+	go func() {
+		defer close(events)
+		// Events are encoded inside the callback: Result is only safe to
+		// read until the callback returns.
+		progress := analyzer.WithProgress(func(event analyzer.ProgressEvent) {
+			data, err := json.Marshal(eventBody(c, event))
+			if err != nil {
+				log.Printf("[ERROR] Failed to encode %s event: %v", event.Type, err)
+				return
+			}
+			select {
+			case events <- serverSentEvent{name: event.Type, data: string(data)}:
+			case <-ctx.Done():
+			}
+		})
+		_, err := webPageAnalyzer.Service.Analyze(ctx, request.WebpageUrl, append(analyzer.RequestOptions(request), progress)...)
+		finished <- err
+	}()
+
+	c.Header("Content-Type", "text/event-stream;charset=utf-8")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	for event := range events {
+		c.SSEvent(event.name, event.data)
+		c.Writer.Flush()
+	}
+
+	if err := <-finished; err != nil {
+		log.Printf("[ERROR] Analysis failed for %s: %v", request.WebpageUrl, err)
+		problem := ProblemForError(err)
+		problem.Instance = c.Request.URL.Path
+		if isLegacy(c) {
+			c.SSEvent("error", legacyError(problem))
+		} else {
+			c.SSEvent("error", problem)
+		}
+		c.Writer.Flush()
+		return
+	}
+	log.Printf("[INFO] Analysis successful for %s", request.WebpageUrl)
+}
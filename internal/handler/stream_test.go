@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamAnalysisHandler_StreamsProgress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := analyzer.DefaultAnalyzerService{Fetcher: analyzer.NewFixtureFetcher(map[string]analyzer.Fixture{
+		"https://example.com":  {Body: `<html><head><title>Streamed</title></head><body><a href="https://example.org/">Out</a></body></html>`},
+		"https://example.org/": {},
+	})}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newTestRequest(`{"webpageUrl":"https://example.com"}`)
+
+	var webPageAnalyzer = WebPageAnalyzer{Service: service}
+	webPageAnalyzer.StreamAnalysisHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream;charset=utf-8", w.Header().Get("Content-Type"))
+	events := readEventNames(w.Body.String())
+	assert.Equal(t, []string{"fetched", "parsed", "check", "check", "check", "check", "check", "check", "link", "check", "done"}, events)
+	assert.Contains(t, w.Body.String(), `"title":"Streamed"`)
+}
+
+func TestStreamAnalysisHandler_GetWithQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/analyzer/stream?webpageUrl=https://example.com", nil)
+	var analyzed string
+	mockService := MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			analyzed = url
+			return model.PageAnalysisResponse{URL: url}, nil
+		},
+	}
+
+	var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+	webPageAnalyzer.StreamAnalysisHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://example.com", analyzed)
+}
+
+func TestStreamAnalysisHandler_MissingURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/analyzer/stream", nil)
+
+	var webPageAnalyzer = WebPageAnalyzer{Service: MockAnalyzerService{}}
+	webPageAnalyzer.StreamAnalysisHandler(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid request format or missing webpageUrl", decodeProblem(t, w.Body).Detail)
+}
+
+func TestStreamAnalysisHandler_ReportsFailureAsEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newTestRequest(`{"webpageUrl":"https://example.com"}`)
+	mockService := MockAnalyzerService{
+		AnalyzeFunc: func(url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{}, fmt.Errorf("failed to fetch the webpage")
+		},
+	}
+
+	var webPageAnalyzer = WebPageAnalyzer{Service: mockService}
+	webPageAnalyzer.StreamAnalysisHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"error"}, readEventNames(w.Body.String()))
+	assert.Contains(t, w.Body.String(), `"detail":"failed to fetch the webpage"`)
+	assert.Contains(t, w.Body.String(), `"code":"internal_error"`)
+}
+
+func readEventNames(stream string) []string {
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(stream))
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event:"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
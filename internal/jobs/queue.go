@@ -0,0 +1,290 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+var (
+	ErrNotFound  = errors.New("analysis job not found")
+	ErrQueueFull = errors.New("analysis queue is full")
+	ErrFinished  = errors.New("analysis job has already finished")
+	ErrClosed    = errors.New("analysis queue is closed")
+)
+
+// Job is a snapshot of an analysis job.
+type Job struct {
+	ID         string                      `json:"id"`
+	Status     Status                      `json:"status"`
+	URL        string                      `json:"url"`
+	Result     *model.PageAnalysisResponse `json:"result,omitempty"`
+	Error      string                      `json:"error,omitempty"`
+	Code       string                      `json:"code,omitempty"`
+	CreatedAt  time.Time                   `json:"createdAt"`
+	StartedAt  *time.Time                  `json:"startedAt,omitempty"`
+	FinishedAt *time.Time                  `json:"finishedAt,omitempty"`
+}
+
+func (j Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+type Config struct {
+	// Workers is the number of analyses run at the same time.
+	Workers int
+	// Capacity is the number of jobs that may wait for a worker.
+	Capacity int
+	// Retention is how long finished jobs stay available.
+	Retention time.Duration
+	// MaxRetained is the number of finished jobs kept; the oldest are
+	// dropped first, even before their retention period is over.
+	MaxRetained int
+}
+
+var DefaultConfig = Config{
+	Workers:     4,
+	Capacity:    100,
+	Retention:   time.Hour,
+	MaxRetained: 1000,
+}
+
+type job struct {
+	Job
+	options []analyzer.Option
+	cancel  context.CancelFunc
+}
+
+// Queue runs analyses in the background on a fixed pool of workers.
+type Queue struct {
+	service analyzer.Service
+	config  Config
+	ctx     context.Context
+	stop    context.CancelFunc
+	pending chan *job
+	wg      sync.WaitGroup
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	closed bool
+}
+
+func NewQueue(service analyzer.Service, config Config) *Queue {
+	if config.Workers <= 0 {
+		config.Workers = DefaultConfig.Workers
+	}
+	if config.Capacity <= 0 {
+		config.Capacity = DefaultConfig.Capacity
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultConfig.Retention
+	}
+	if config.MaxRetained <= 0 {
+		config.MaxRetained = DefaultConfig.MaxRetained
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	q := &Queue{
+		service: service,
+		config:  config,
+		ctx:     ctx,
+		stop:    stop,
+		pending: make(chan *job, config.Capacity),
+		jobs:    make(map[string]*job),
+	}
+	for i := 0; i < config.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+func (q *Queue) Submit(url string, options ...analyzer.Option) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return Job{}, ErrClosed
+	}
+	q.purge()
+
+	j := &job{
+		Job: Job{
+			ID:        newID(),
+			Status:    StatusQueued,
+			URL:       url,
+			CreatedAt: time.Now(),
+		},
+		options: options,
+	}
+	select {
+	case q.pending <- j:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[j.ID] = j
+	log.Printf("[INFO] Queued analysis job %s for %s", j.ID, url)
+	return j.Job, nil
+}
+
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return j.Job, nil
+}
+
+// Cancel stops a queued or running job. A running analysis is interrupted
+// through its context and its partial result is kept.
+func (q *Queue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if j.Finished() {
+		return j.Job, ErrFinished
+	}
+	if j.cancel != nil {
+		j.cancel()
+	}
+	q.finish(j, StatusCancelled)
+	q.purge()
+	log.Printf("[INFO] Cancelled analysis job %s", id)
+	return j.Job, nil
+}
+
+// Close cancels every unfinished job and waits for the workers to stop.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	for _, j := range q.jobs {
+		if !j.Finished() {
+			q.finish(j, StatusCancelled)
+		}
+	}
+	close(q.pending)
+	q.mu.Unlock()
+
+	q.stop()
+	q.wg.Wait()
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for j := range q.pending {
+		ctx, ok := q.start(j)
+		if !ok {
+			continue
+		}
+		result, err := q.service.Analyze(ctx, j.URL, j.options...)
+		q.complete(j, result, err)
+	}
+}
+
+func (q *Queue) start(j *job) (context.Context, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if j.Status != StatusQueued {
+		return nil, false
+	}
+	ctx, cancel := context.WithCancel(q.ctx)
+	now := time.Now()
+	j.Status = StatusRunning
+	j.StartedAt = &now
+	j.cancel = cancel
+	return ctx, true
+}
+
+func (q *Queue) complete(j *job, result model.PageAnalysisResponse, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j.cancel()
+	if j.Status == StatusCancelled {
+		if err == nil {
+			j.Result = &result
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] Analysis job %s failed: %v", j.ID, err)
+		j.Error = err.Error()
+		j.Code = analyzer.ErrorCode(err)
+		q.finish(j, StatusFailed)
+		q.purge()
+		return
+	}
+	j.Result = &result
+	q.finish(j, StatusSucceeded)
+	q.purge()
+	log.Printf("[INFO] Analysis job %s finished", j.ID)
+}
+
+func (q *Queue) finish(j *job, status Status) {
+	now := time.Now()
+	j.Status = status
+	j.FinishedAt = &now
+}
+
+// purge drops finished jobs older than the retention period, then the
+// oldest finished jobs beyond MaxRetained. It runs whenever a job is
+// submitted or finishes, which keeps memory bounded without a background
+// sweeper.
+func (q *Queue) purge() {
+	cutoff := time.Now().Add(-q.config.Retention)
+	var finished []*job
+	for id, j := range q.jobs {
+		if !j.Finished() {
+			continue
+		}
+		if j.FinishedAt.Before(cutoff) {
+			delete(q.jobs, id)
+			continue
+		}
+		finished = append(finished, j)
+	}
+	if len(finished) <= q.config.MaxRetained {
+		return
+	}
+	sort.Slice(finished, func(i, k int) bool { return finished[i].FinishedAt.Before(*finished[k].FinishedAt) })
+	for _, j := range finished[:len(finished)-q.config.MaxRetained] {
+		delete(q.jobs, j.ID)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	"github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockAnalyzerService struct {
+	AnalyzeFunc func(ctx context.Context, url string) (model.PageAnalysisResponse, error)
+}
+
+func (s MockAnalyzerService) Analyze(ctx context.Context, url string, options ...analyzer.Option) (model.PageAnalysisResponse, error) {
+	return s.AnalyzeFunc(ctx, url)
+}
+
+func waitForStatus(t *testing.T, q *Queue, id string, status Status) Job {
+	t.Helper()
+	var job Job
+	assert.Eventually(t, func() bool {
+		job, _ = q.Get(id)
+		return job.Status == status
+	}, time.Second, time.Millisecond)
+	return job
+}
+
+func TestQueue_RunsJobToCompletion(t *testing.T) {
+	q := NewQueue(MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{URL: url, Title: "Done"}, nil
+		},
+	}, Config{Workers: 1})
+	defer q.Close()
+
+	submitted, err := q.Submit("https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusQueued, submitted.Status)
+	assert.Len(t, submitted.ID, 32)
+
+	job := waitForStatus(t, q, submitted.ID, StatusSucceeded)
+	assert.Equal(t, "Done", job.Result.Title)
+	assert.NotNil(t, job.StartedAt)
+	assert.NotNil(t, job.FinishedAt)
+}
+
+func TestQueue_RecordsFailure(t *testing.T) {
+	q := NewQueue(MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{}, &analyzer.Error{Code: analyzer.CodeInvalidInput, Message: "invalid URL format"}
+		},
+	}, Config{Workers: 1})
+	defer q.Close()
+
+	submitted, err := q.Submit("invalid-url")
+	assert.NoError(t, err)
+
+	job := waitForStatus(t, q, submitted.ID, StatusFailed)
+	assert.Equal(t, "invalid URL format", job.Error)
+	assert.Equal(t, analyzer.CodeInvalidInput, job.Code)
+	assert.Nil(t, job.Result)
+}
+
+func TestQueue_CancelRunningJobKeepsPartialResult(t *testing.T) {
+	started := make(chan struct{})
+	q := NewQueue(MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			close(started)
+			<-ctx.Done()
+			return model.PageAnalysisResponse{URL: url, Title: "Partial", TimedOut: true}, nil
+		},
+	}, Config{Workers: 1})
+	defer q.Close()
+
+	submitted, err := q.Submit("https://example.com")
+	assert.NoError(t, err)
+	<-started
+
+	cancelled, err := q.Cancel(submitted.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCancelled, cancelled.Status)
+
+	assert.Eventually(t, func() bool {
+		job, _ := q.Get(submitted.ID)
+		return job.Result != nil
+	}, time.Second, time.Millisecond)
+	job, _ := q.Get(submitted.ID)
+	assert.Equal(t, StatusCancelled, job.Status)
+	assert.True(t, job.Result.TimedOut)
+
+	_, err = q.Cancel(submitted.ID)
+	assert.ErrorIs(t, err, ErrFinished)
+}
+
+func TestQueue_CancelQueuedJobNeverRuns(t *testing.T) {
+	release := make(chan struct{})
+	var analyzed []string
+	q := NewQueue(MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			<-release
+			analyzed = append(analyzed, url)
+			return model.PageAnalysisResponse{URL: url}, nil
+		},
+	}, Config{Workers: 1})
+
+	first, err := q.Submit("https://example.com/first")
+	assert.NoError(t, err)
+	second, err := q.Submit("https://example.com/second")
+	assert.NoError(t, err)
+
+	_, err = q.Cancel(second.ID)
+	assert.NoError(t, err)
+	close(release)
+	waitForStatus(t, q, first.ID, StatusSucceeded)
+	q.Close()
+
+	assert.Equal(t, []string{"https://example.com/first"}, analyzed)
+}
+
+func TestQueue_RejectsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	q := NewQueue(MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return model.PageAnalysisResponse{}, nil
+		},
+	}, Config{Workers: 1, Capacity: 1})
+	defer q.Close()
+
+	running, err := q.Submit("https://example.com/1")
+	assert.NoError(t, err)
+	waitForStatus(t, q, running.ID, StatusRunning)
+	_, err = q.Submit("https://example.com/2")
+	assert.NoError(t, err)
+
+	_, err = q.Submit("https://example.com/3")
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+func TestQueue_UnknownJob(t *testing.T) {
+	q := NewQueue(MockAnalyzerService{}, Config{Workers: 1})
+	defer q.Close()
+
+	_, err := q.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = q.Cancel("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestQueue_KeepsAtMostMaxRetainedFinishedJobs(t *testing.T) {
+	q := NewQueue(MockAnalyzerService{
+		AnalyzeFunc: func(ctx context.Context, url string) (model.PageAnalysisResponse, error) {
+			return model.PageAnalysisResponse{URL: url}, nil
+		},
+	}, Config{Workers: 1, MaxRetained: 2})
+	defer q.Close()
+
+	var ids []string
+	for _, url := range []string{"https://example.com/1", "https://example.com/2", "https://example.com/3", "https://example.com/4"} {
+		submitted, err := q.Submit(url)
+		assert.NoError(t, err)
+		waitForStatus(t, q, submitted.ID, StatusSucceeded)
+		ids = append(ids, submitted.ID)
+	}
+
+	for _, id := range ids[:2] {
+		_, err := q.Get(id)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	for _, id := range ids[2:] {
+		job, err := q.Get(id)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSucceeded, job.Status)
+	}
+}
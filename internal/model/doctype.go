@@ -0,0 +1,33 @@
+package model
+
+const (
+	DoctypeFamilyHTML    = "HTML"
+	DoctypeFamilyXHTML   = "XHTML"
+	DoctypeFamilyUnknown = "Unknown"
+)
+
+// Rendering modes a browser picks from the doctype, as defined by the HTML
+// standard.
+const (
+	RenderingStandards     = "standards"
+	RenderingLimitedQuirks = "limited-quirks"
+	RenderingQuirks        = "quirks"
+)
+
+// Doctype describes the document type declaration of a page. Variant is
+// Strict, Transitional or Frameset for HTML 4 and XHTML 1.0, and Basic,
+// Mobile or RDFa for those XHTML profiles. DTDURL is the system identifier,
+// or the well-known DTD of the version when the page gives none.
+type Doctype struct {
+	Raw           string `json:"raw,omitempty"`
+	Name          string `json:"name,omitempty"`
+	PublicID      string `json:"publicId,omitempty"`
+	SystemID      string `json:"systemId,omitempty"`
+	Family        string `json:"family"`
+	Version       string `json:"version,omitempty"`
+	Variant       string `json:"variant,omitempty"`
+	DTDURL        string `json:"dtdUrl,omitempty"`
+	RenderingMode string `json:"renderingMode"`
+	Missing       bool   `json:"missing"`
+	Malformed     bool   `json:"malformed"`
+}
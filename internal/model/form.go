@@ -0,0 +1,58 @@
+package model
+
+// Kinds of form the analyzer recognises.
+const (
+	FormLogin         = "login"
+	FormSignup        = "signup"
+	FormPasswordReset = "password_reset"
+	FormSearch        = "search"
+	FormNewsletter    = "newsletter"
+	FormPayment       = "payment"
+	FormOther         = "other"
+)
+
+// Codes of the issues found on a form.
+const (
+	FormIssueInsecureAction       = "insecure_action"
+	FormIssueCredentialsInURL     = "credentials_in_url"
+	FormIssueCrossOrigin          = "cross_origin_action"
+	FormIssueMissingCSRFToken     = "missing_csrf_token"
+	FormIssueMissingAutocomplete  = "missing_autocomplete"
+	FormIssueMissingLabel         = "missing_label"
+	FormIssueFileWithoutMultipart = "file_without_multipart"
+)
+
+// Form is one <form> of the page. Kind is the most likely purpose of the
+// form and Confidence, from 0 to 1, how strongly its fields and wording
+// point to it; FormOther forms have a confidence of 0. Action is resolved
+// against the page URL. CrossOrigin is set when the action is on another
+// origin than the page, and HasCSRFToken when a hidden field looks like an
+// anti-forgery token.
+type Form struct {
+	Index            int         `json:"index"`
+	ID               string      `json:"id,omitempty"`
+	Name             string      `json:"name,omitempty"`
+	Method           string      `json:"method"`
+	Action           string      `json:"action"`
+	Enctype          string      `json:"enctype"`
+	Kind             string      `json:"kind"`
+	Confidence       float64     `json:"confidence"`
+	Fields           []FormField `json:"fields"`
+	SubmitsOverHTTPS bool        `json:"submitsOverHttps"`
+	CrossOrigin      bool        `json:"crossOrigin"`
+	HasCSRFToken     bool        `json:"hasCsrfToken"`
+	Issues           []Issue     `json:"issues,omitempty"`
+}
+
+// FormField is an input, select or textarea owned by a form, including
+// controls placed elsewhere that name the form in their form attribute.
+// Type is the input type, or "select" and "textarea". Label is the text of
+// the field's label, aria-label, aria-labelledby or title.
+type FormField struct {
+	Name         string `json:"name,omitempty"`
+	ID           string `json:"id,omitempty"`
+	Type         string `json:"type"`
+	Label        string `json:"label,omitempty"`
+	Required     bool   `json:"required"`
+	Autocomplete string `json:"autocomplete,omitempty"`
+}
@@ -0,0 +1,21 @@
+package model
+
+// Codes of the issues found in the heading hierarchy.
+const (
+	HeadingIssueMissingH1    = "missing_h1"
+	HeadingIssueMultipleH1   = "multiple_h1"
+	HeadingIssueSkippedLevel = "skipped_level"
+	HeadingIssueEmpty        = "empty_heading"
+	HeadingIssueHidden       = "hidden_heading"
+)
+
+// HeadingNode is a heading of the document outline with the headings of
+// lower rank that follow it. Hidden headings, inside aria-hidden, hidden or
+// display:none elements, stay in the outline but are not taken into account
+// when checking for skipped levels.
+type HeadingNode struct {
+	Level    int           `json:"level"`
+	Text     string        `json:"text"`
+	Hidden   bool          `json:"hidden"`
+	Children []HeadingNode `json:"children,omitempty"`
+}
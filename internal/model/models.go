@@ -1,16 +1,144 @@
 package model
 
 type PageAnalysisRequest struct {
-	WebpageUrl string `json:"webpageUrl" binding:"required"`
+	WebpageUrl     string           `json:"webpageUrl" binding:"required"`
+	Checks         []string         `json:"checks"`
+	DisabledChecks []string         `json:"disabledChecks"`
+	TimeoutMs      int              `json:"timeoutMs"`
+	LinkCheck      *LinkCheckLimits `json:"linkCheck"`
+	IncludeLinks   bool             `json:"includeLinks"`
+	// CheckInternalLinks defaults to the server setting when omitted.
+	CheckInternalLinks *bool `json:"checkInternalLinks"`
+	// RestrictedStatusCodes replaces the server list of link statuses
+	// reported as restricted instead of broken; [] restricts none.
+	RestrictedStatusCodes []int             `json:"restrictedStatusCodes"`
+	UserAgent             string            `json:"userAgent"`
+	Headers               map[string]string `json:"headers"`
+	// Policy is evaluated against the result and reported in it; a failed
+	// policy does not fail the request.
+	Policy *Policy `json:"policy"`
+}
+
+type LinkCheckLimits struct {
+	Workers           int `json:"workers"`
+	PerHost           int `json:"perHost"`
+	PerHostIntervalMs int `json:"perHostIntervalMs"`
+}
+
+// APIVersion is the version of the response contract; the API is served
+// under /v1 and the JSON Schema of AnalyzerResponse is published for it.
+const APIVersion = "v1"
+
+// AnalyzerResponse is the body of a successful analysis.
+type AnalyzerResponse struct {
+	URL     string               `json:"url"`
+	Content PageAnalysisResponse `json:"content"`
 }
 
 type PageAnalysisResponse struct {
-	URL               string
-	HTMLVersion       string
-	Title             string
-	HeadingCounts     map[string]int
-	InternalLinks     int
-	ExternalLinks     int
-	InaccessibleLinks int
-	HasLoginForm      bool
+	URL                       string          `json:"url"`
+	FinalURL                  string          `json:"finalUrl"`
+	Redirects                 *RedirectChain  `json:"redirects,omitempty"`
+	HTMLVersion               string          `json:"htmlVersion"`
+	Doctype                   *Doctype        `json:"doctype,omitempty"`
+	Title                     string          `json:"title"`
+	HeadingCounts             map[string]int  `json:"headingCounts"`
+	Outline                   []HeadingNode   `json:"outline,omitempty"`
+	HeadingIssues             []Issue         `json:"headingIssues,omitempty"`
+	InternalLinks             int             `json:"internalLinks"`
+	ExternalLinks             int             `json:"externalLinks"`
+	InaccessibleLinks         int             `json:"inaccessibleLinks"`
+	InaccessibleInternalLinks int             `json:"inaccessibleInternalLinks"`
+	InaccessibleExternalLinks int             `json:"inaccessibleExternalLinks"`
+	LinkStatusCounts          map[string]int  `json:"linkStatusCounts"`
+	SEO                       *SEO            `json:"seo,omitempty"`
+	HasLoginForm              bool            `json:"hasLoginForm"`
+	Forms                     []Form          `json:"forms,omitempty"`
+	LinkCheckLimits           LinkCheckLimits `json:"linkCheckLimits"`
+	Links                     []LinkDetail    `json:"links,omitempty"`
+	TimedOut                  bool            `json:"timedOut"`
+	Warnings                  []Issue         `json:"warnings,omitempty"`
+	Errors                    []Issue         `json:"errors,omitempty"`
+	// Checks lists the checks that completed, in the order they ran.
+	Checks   []string       `json:"checks,omitempty"`
+	Sections map[string]any `json:"sections,omitempty"`
+	Policy   *PolicyReport  `json:"policy,omitempty"`
+}
+
+const (
+	LinkInternal = "internal"
+	LinkExternal = "external"
+	LinkInvalid  = "invalid"
+)
+
+const (
+	LinkStatusOK          = "ok"
+	LinkStatusBroken      = "broken"
+	LinkStatusRestricted  = "restricted"
+	LinkStatusRateLimited = "rate_limited"
+	LinkStatusTimeout     = "timeout"
+	LinkStatusError       = "error"
+	LinkStatusInvalid     = "invalid"
+	LinkStatusBlocked     = "blocked"
+	LinkStatusUnchecked   = "unchecked"
+)
+
+const (
+	LinkErrorInvalidURL = "invalid_url"
+	LinkErrorNetwork    = "network"
+	LinkErrorTimeout    = "timeout"
+	LinkErrorHTTPStatus = "http_status"
+	LinkErrorRedirect   = "redirect"
+	LinkErrorBlocked    = "blocked"
+)
+
+type LinkDetail struct {
+	URL            string         `json:"url"`
+	Href           string         `json:"href"`
+	Text           string         `json:"text"`
+	Rel            string         `json:"rel,omitempty"`
+	Target         string         `json:"target,omitempty"`
+	Classification string         `json:"classification"`
+	Status         string         `json:"status"`
+	Checked        bool           `json:"checked"`
+	Method         string         `json:"method,omitempty"`
+	Attempts       int            `json:"attempts,omitempty"`
+	StatusCode     int            `json:"statusCode,omitempty"`
+	LatencyMs      int64          `json:"latencyMs,omitempty"`
+	Redirects      *RedirectChain `json:"redirects,omitempty"`
+	ErrorKind      string         `json:"errorKind,omitempty"`
+	Line           int            `json:"line,omitempty"`
+	Column         int            `json:"column,omitempty"`
+}
+
+type RedirectChain struct {
+	Hops           []RedirectHop `json:"hops"`
+	HopCount       int           `json:"hopCount"`
+	FinalURL       string        `json:"finalUrl"`
+	Loop           bool          `json:"loop"`
+	TooManyHops    bool          `json:"tooManyHops"`
+	HTTPSDowngrade bool          `json:"httpsDowngrade"`
+}
+
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Location   string `json:"location"`
+}
+
+const (
+	IssueBodyTruncated   = "body_truncated"
+	IssueCheckFailed     = "check_failed"
+	IssueCheckTimedOut   = "check_timed_out"
+	IssueCheckSkipped    = "check_skipped"
+	IssueLinksUnchecked  = "links_unchecked"
+	IssueLinkCheckFailed = "link_check_failed"
+)
+
+// Issue is a problem met during an analysis that did not stop it. Check
+// names the check it belongs to; it is empty for the page as a whole.
+type Issue struct {
+	Code    string `json:"code"`
+	Check   string `json:"check,omitempty"`
+	Message string `json:"message"`
 }
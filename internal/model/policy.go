@@ -0,0 +1,32 @@
+package model
+
+// Policy is a list of assertions over the fields of a PageAnalysisResponse.
+type Policy struct {
+	Rules []PolicyRule `json:"rules" yaml:"rules"`
+}
+
+// PolicyRule compares the response value at Field with Value using Op.
+// Field is a dotted path such as "headingCounts.h1" or "sections.name.key",
+// matched case-insensitively; Default is used when the path does not exist.
+type PolicyRule struct {
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Field   string `json:"field" yaml:"field"`
+	Op      string `json:"op" yaml:"op"`
+	Value   any    `json:"value,omitempty" yaml:"value,omitempty"`
+	Default any    `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+type PolicyReport struct {
+	Passed  bool               `json:"passed"`
+	Results []PolicyRuleResult `json:"results"`
+}
+
+type PolicyRuleResult struct {
+	Name        string `json:"name"`
+	Field       string `json:"field"`
+	Op          string `json:"op"`
+	Expected    any    `json:"expected,omitempty"`
+	Actual      any    `json:"actual"`
+	Passed      bool   `json:"passed"`
+	Explanation string `json:"explanation"`
+}
@@ -0,0 +1,21 @@
+package model
+
+// ProblemContentType is the media type of Problem responses.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is the stable,
+// machine-readable error code; Type is derived from it. UpstreamStatus,
+// Redirects and Job are extension members: the status of the page for
+// upstream_status, the redirects followed before they failed, and the
+// finished job a conflict is about.
+type Problem struct {
+	Type           string         `json:"type"`
+	Title          string         `json:"title"`
+	Status         int            `json:"status"`
+	Detail         string         `json:"detail,omitempty"`
+	Instance       string         `json:"instance,omitempty"`
+	Code           string         `json:"code"`
+	UpstreamStatus int            `json:"upstreamStatus,omitempty"`
+	Redirects      *RedirectChain `json:"redirects,omitempty"`
+	Job            any            `json:"job,omitempty"`
+}
@@ -0,0 +1,53 @@
+package model
+
+// Codes of the issues found in the SEO metadata.
+const (
+	SEOIssueMissingTitle         = "missing_title"
+	SEOIssueTitleTooShort        = "title_too_short"
+	SEOIssueTitleTooLong         = "title_too_long"
+	SEOIssueMultipleTitles       = "multiple_titles"
+	SEOIssueMissingDescription   = "missing_description"
+	SEOIssueDescriptionTooShort  = "description_too_short"
+	SEOIssueDescriptionTooLong   = "description_too_long"
+	SEOIssueMultipleDescriptions = "multiple_descriptions"
+	SEOIssueDuplicateDescription = "description_duplicates_title"
+	SEOIssueNotIndexable         = "not_indexable"
+	SEOIssueMultipleCanonicals   = "multiple_canonicals"
+	SEOIssueMetaRefresh          = "meta_refresh"
+	SEOIssueMissingViewport      = "missing_viewport"
+)
+
+// SEO is the search engine metadata of a page. Title is the first <title>
+// of the head. Indexable and Followable combine the robots meta tag and the
+// X-Robots-Tag header; directives aimed at a single crawler are ignored.
+// URLs are resolved against the page URL. Lengths count characters.
+type SEO struct {
+	Title             string              `json:"title"`
+	TitleLength       int                 `json:"titleLength"`
+	Description       string              `json:"description"`
+	DescriptionLength int                 `json:"descriptionLength"`
+	Robots            string              `json:"robots,omitempty"`
+	XRobotsTag        []string            `json:"xRobotsTag,omitempty"`
+	Indexable         bool                `json:"indexable"`
+	Followable        bool                `json:"followable"`
+	Canonical         string              `json:"canonical,omitempty"`
+	Alternates        []HreflangAlternate `json:"alternates,omitempty"`
+	Prev              string              `json:"prev,omitempty"`
+	Next              string              `json:"next,omitempty"`
+	Refresh           *MetaRefresh        `json:"refresh,omitempty"`
+	Viewport          string              `json:"viewport,omitempty"`
+	Issues            []Issue             `json:"issues,omitempty"`
+}
+
+// HreflangAlternate is a link to a translation of the page.
+type HreflangAlternate struct {
+	Hreflang string `json:"hreflang"`
+	URL      string `json:"url"`
+}
+
+// MetaRefresh is a <meta http-equiv="refresh">; URL is empty when the page
+// reloads itself.
+type MetaRefresh struct {
+	DelaySeconds int    `json:"delaySeconds"`
+	URL          string `json:"url,omitempty"`
+}
@@ -0,0 +1,131 @@
+package netguard
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// Code is the machine-readable error code reported for blocked targets.
+const Code = "blocked_target"
+
+// DefaultBlocked are the ranges a server-side fetch must never reach:
+// loopback, link-local (including cloud metadata endpoints), private and
+// carrier-grade NAT networks, the NAT64, 6to4 and Teredo prefixes that can
+// embed and reach any of them, and unspecified, multicast and reserved space.
+var DefaultBlocked = mustParsePrefixes(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"2001::/32",
+	"2002::/16",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// Guard decides which IP addresses outgoing connections may reach. It is
+// applied when dialing, to the address actually connected to, so it also
+// covers redirects and hostnames that resolve (or re-resolve) to internal
+// addresses. A nil Guard allows everything.
+type Guard struct {
+	blocked []netip.Prefix
+	allowed []netip.Prefix
+}
+
+// New returns a Guard blocking DefaultBlocked plus blocked. Addresses in
+// allowed are permitted even when a blocked range contains them.
+func New(blocked []netip.Prefix, allowed []netip.Prefix) *Guard {
+	return &Guard{
+		blocked: append(append([]netip.Prefix{}, DefaultBlocked...), blocked...),
+		allowed: append([]netip.Prefix{}, allowed...),
+	}
+}
+
+// BlockedError is returned for connections to a blocked address.
+type BlockedError struct {
+	Address netip.Addr
+	Range   netip.Prefix
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("target address %s is not allowed (%s)", e.Address, e.Range)
+}
+
+func (g *Guard) Check(addr netip.Addr) error {
+	if g == nil {
+		return nil
+	}
+	addr = addr.Unmap()
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	for _, prefix := range g.blocked {
+		if prefix.Contains(addr) {
+			return &BlockedError{Address: addr, Range: prefix}
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control hook that refuses blocked addresses.
+func (g *Guard) Control(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("unexpected dial address %q: %w", address, err)
+	}
+	return g.Check(addr)
+}
+
+// ParsePrefixes parses CIDR ranges; a bare IP address is taken as a range
+// holding only that address.
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", value, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", value, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func mustParsePrefixes(values ...string) []netip.Prefix {
+	prefixes, err := ParsePrefixes(values)
+	if err != nil {
+		panic(err)
+	}
+	return prefixes
+}
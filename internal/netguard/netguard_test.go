@@ -0,0 +1,87 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard_Check(t *testing.T) {
+	extra, err := ParsePrefixes([]string{"203.0.113.0/24"})
+	assert.NoError(t, err)
+	allowed, err := ParsePrefixes([]string{"10.1.2.3"})
+	assert.NoError(t, err)
+	guard := New(extra, allowed)
+
+	tests := []struct {
+		address string
+		blocked bool
+	}{
+		{address: "127.0.0.1", blocked: true},
+		{address: "169.254.169.254", blocked: true},
+		{address: "10.0.0.8", blocked: true},
+		{address: "172.20.1.1", blocked: true},
+		{address: "192.168.1.10", blocked: true},
+		{address: "0.0.0.0", blocked: true},
+		{address: "::1", blocked: true},
+		{address: "fd12:3456::1", blocked: true},
+		{address: "fe80::1", blocked: true},
+		{address: "::ffff:127.0.0.1", blocked: true},
+		{address: "64:ff9b::a9fe:a9fe", blocked: true},
+		{address: "2002:7f00:1::", blocked: true},
+		{address: "2001:0:4136:e378:8000:63bf:f5ff:fffe", blocked: true},
+		{address: "203.0.113.7", blocked: true},
+		{address: "10.1.2.3", blocked: false},
+		{address: "93.184.216.34", blocked: false},
+		{address: "2606:2800:220:1::1", blocked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := guard.Check(netip.MustParseAddr(tt.address))
+			if tt.blocked {
+				var blocked *BlockedError
+				assert.True(t, errors.As(err, &blocked))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGuard_NilAllowsEverything(t *testing.T) {
+	var guard *Guard
+
+	assert.NoError(t, guard.Check(netip.MustParseAddr("127.0.0.1")))
+}
+
+func TestGuard_ControlRefusesDial(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	dialer := net.Dialer{Control: New(nil, nil).Control}
+	_, err = dialer.Dial("tcp", listener.Addr().String())
+
+	var blocked *BlockedError
+	assert.True(t, errors.As(err, &blocked))
+	assert.Equal(t, "target address 127.0.0.1 is not allowed (127.0.0.0/8)", blocked.Error())
+}
+
+func TestGuard_ControlRefusesNAT64Dial(t *testing.T) {
+	dialer := net.Dialer{Control: New(nil, nil).Control}
+	_, err := dialer.Dial("tcp", "[64:ff9b::a9fe:a9fe]:80")
+
+	var blocked *BlockedError
+	assert.True(t, errors.As(err, &blocked))
+	assert.Equal(t, netip.MustParsePrefix("64:ff9b::/96"), blocked.Range)
+}
+
+func TestParsePrefixes_Invalid(t *testing.T) {
+	_, err := ParsePrefixes([]string{"10.0.0.0/33"})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,291 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule operators. Ordering operators compare numbers; lists and objects
+// compare by their number of elements.
+const (
+	OpEqual        = "=="
+	OpNotEqual     = "!="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpEmpty        = "empty"
+	OpNotEmpty     = "notEmpty"
+	OpContains     = "contains"
+	OpMatches      = "matches"
+)
+
+var operators = map[string]bool{
+	OpEqual: true, OpNotEqual: true, OpLess: true, OpLessEqual: true, OpGreater: true,
+	OpGreaterEqual: true, OpEmpty: true, OpNotEmpty: true, OpContains: true, OpMatches: true,
+}
+
+// Parse reads a policy written in YAML or JSON.
+func Parse(data []byte) (Policy, error) {
+	var policy Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return Policy{}, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := Validate(policy); err != nil {
+		return Policy{}, err
+	}
+	return policy, nil
+}
+
+func Load(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	return Parse(data)
+}
+
+// Validate reports the first rule that cannot be evaluated.
+func Validate(policy Policy) error {
+	for i, rule := range policy.Rules {
+		if rule.Field == "" {
+			return fmt.Errorf("invalid policy: rule %d has no field", i+1)
+		}
+		if !operators[rule.Op] {
+			return fmt.Errorf("invalid policy: rule %d has unknown op %q", i+1, rule.Op)
+		}
+		if rule.Op == OpMatches {
+			pattern, ok := rule.Value.(string)
+			if !ok {
+				return fmt.Errorf("invalid policy: rule %d needs a string pattern", i+1)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid policy: rule %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate checks every rule against response. The policy passes when all
+// rules pass.
+func Evaluate(policy Policy, response PageAnalysisResponse) PolicyReport {
+	document := toDocument(response)
+	report := PolicyReport{Passed: true, Results: make([]PolicyRuleResult, 0, len(policy.Rules))}
+	for _, rule := range policy.Rules {
+		result := evaluateRule(rule, document)
+		report.Passed = report.Passed && result.Passed
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+func evaluateRule(rule PolicyRule, document any) PolicyRuleResult {
+	result := PolicyRuleResult{Name: rule.Name, Field: rule.Field, Op: rule.Op, Expected: rule.Value}
+	if result.Name == "" {
+		result.Name = strings.TrimSpace(fmt.Sprintf("%s %s %s", rule.Field, rule.Op, formatValue(rule.Value)))
+	}
+
+	actual, found := lookup(document, rule.Field)
+	if !found {
+		if rule.Default == nil {
+			result.Explanation = fmt.Sprintf("%s is missing", rule.Field)
+			result.Passed = rule.Op == OpEmpty
+			return result
+		}
+		actual = rule.Default
+	}
+	result.Actual = summarize(actual)
+
+	passed, err := compare(rule.Op, actual, rule.Value)
+	if err != nil {
+		result.Explanation = fmt.Sprintf("%s: %v", rule.Field, err)
+		return result
+	}
+	result.Passed = passed
+	if passed {
+		result.Explanation = fmt.Sprintf("%s is %s", rule.Field, formatValue(actual))
+	} else {
+		result.Explanation = fmt.Sprintf("%s is %s, expected %s %s", rule.Field, formatValue(actual), rule.Op, formatValue(rule.Value))
+		if rule.Op == OpEmpty || rule.Op == OpNotEmpty {
+			result.Explanation = fmt.Sprintf("%s is %s, expected %s", rule.Field, formatValue(actual), rule.Op)
+		}
+	}
+	return result
+}
+
+func compare(op string, actual any, expected any) (bool, error) {
+	switch op {
+	case OpEmpty:
+		return isEmpty(actual), nil
+	case OpNotEmpty:
+		return !isEmpty(actual), nil
+	case OpEqual:
+		return equal(actual, expected), nil
+	case OpNotEqual:
+		return !equal(actual, expected), nil
+	case OpContains:
+		return contains(actual, expected)
+	case OpMatches:
+		text, ok := actual.(string)
+		if !ok {
+			return false, fmt.Errorf("%s is not text", formatValue(actual))
+		}
+		return regexp.MustCompile(expected.(string)).MatchString(text), nil
+	}
+
+	a, ok := number(actual)
+	if !ok {
+		return false, fmt.Errorf("%s is not a number", formatValue(actual))
+	}
+	b, ok := number(expected)
+	if !ok {
+		return false, fmt.Errorf("%s is not a number", formatValue(expected))
+	}
+	switch op {
+	case OpLess:
+		return a < b, nil
+	case OpLessEqual:
+		return a <= b, nil
+	case OpGreater:
+		return a > b, nil
+	default:
+		return a >= b, nil
+	}
+}
+
+// toDocument turns the response into the generic form it has on the wire,
+// so rules see the same names and values as API clients, sections included.
+func toDocument(response PageAnalysisResponse) any {
+	data, _ := json.Marshal(response)
+	var document any
+	json.Unmarshal(data, &document)
+	return document
+}
+
+func lookup(document any, path string) (any, bool) {
+	current := document
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[segment]
+			if !ok {
+				for key, v := range node {
+					if strings.EqualFold(key, segment) {
+						value, ok = v, true
+						break
+					}
+				}
+			}
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func equal(actual any, expected any) bool {
+	if a, ok := number(actual); ok {
+		b, ok := number(expected)
+		return ok && a == b
+	}
+	return reflect.DeepEqual(actual, expected)
+}
+
+func contains(actual any, expected any) (bool, error) {
+	switch v := actual.(type) {
+	case string:
+		text, ok := expected.(string)
+		return ok && strings.Contains(v, text), nil
+	case []any:
+		for _, item := range v {
+			if equal(item, expected) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]any:
+		key, ok := expected.(string)
+		_, exists := v[key]
+		return ok && exists, nil
+	}
+	return false, fmt.Errorf("%s cannot contain values", formatValue(actual))
+}
+
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case []any:
+		return float64(len(v)), true
+	case map[string]any:
+		return float64(len(v)), true
+	}
+	return 0, false
+}
+
+// summarize keeps reports small: lists and objects are reported by size.
+func summarize(value any) any {
+	switch v := value.(type) {
+	case []any:
+		return len(v)
+	case map[string]any:
+		return len(v)
+	}
+	return value
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strconv.Quote(v)
+	case []any:
+		return fmt.Sprintf("%d item(s)", len(v))
+	case map[string]any:
+		return fmt.Sprintf("%d entries", len(v))
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"testing"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var sampleResponse = PageAnalysisResponse{
+	URL:               "https://example.com",
+	HTMLVersion:       "HTML5",
+	Title:             "Example Domain",
+	HeadingCounts:     map[string]int{"h2": 3},
+	InternalLinks:     4,
+	InaccessibleLinks: 2,
+	HasLoginForm:      true,
+	Warnings:          []Issue{{Code: IssueBodyTruncated, Message: "truncated"}},
+	Sections:          map[string]any{"wordCount": map[string]any{"words": 120}},
+}
+
+func TestEvaluate_Rules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        PolicyRule
+		passed      bool
+		explanation string
+	}{
+		{"no inaccessible links", PolicyRule{Field: "InaccessibleLinks", Op: OpLessEqual, Value: 0}, false, "InaccessibleLinks is 2, expected <= 0"},
+		{"case-insensitive path", PolicyRule{Field: "internalLinks", Op: OpGreater, Value: 3.0}, true, "internalLinks is 4"},
+		{"has title", PolicyRule{Field: "Title", Op: OpNotEmpty}, true, `Title is "Example Domain"`},
+		{"missing h1", PolicyRule{Field: "HeadingCounts.h1", Op: OpGreaterEqual, Value: 1}, false, "HeadingCounts.h1 is missing"},
+		{"missing h1 with default", PolicyRule{Field: "HeadingCounts.h1", Op: OpLessEqual, Value: 1, Default: 0}, true, "HeadingCounts.h1 is 0"},
+		{"html version", PolicyRule{Field: "HTMLVersion", Op: OpEqual, Value: "HTML5"}, true, `HTMLVersion is "HTML5"`},
+		{"boolean", PolicyRule{Field: "HasLoginForm", Op: OpEqual, Value: false}, false, "HasLoginForm is true, expected == false"},
+		{"list length", PolicyRule{Field: "Warnings", Op: OpEqual, Value: 0}, false, "Warnings is 1 item(s), expected == 0"},
+		{"list index", PolicyRule{Field: "Warnings.0.Code", Op: OpEqual, Value: IssueBodyTruncated}, true, `Warnings.0.Code is "body_truncated"`},
+		{"plugin section", PolicyRule{Field: "Sections.wordCount.words", Op: OpGreaterEqual, Value: 100}, true, "Sections.wordCount.words is 120"},
+		{"contains", PolicyRule{Field: "Title", Op: OpContains, Value: "Domain"}, true, `Title is "Example Domain"`},
+		{"matches", PolicyRule{Field: "URL", Op: OpMatches, Value: "^https://"}, true, `URL is "https://example.com"`},
+		{"not a number", PolicyRule{Field: "Title", Op: OpLess, Value: 3}, false, `Title: "Example Domain" is not a number`},
+		{"empty missing field", PolicyRule{Field: "Links", Op: OpEmpty}, true, "Links is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Evaluate(Policy{Rules: []PolicyRule{tt.rule}}, sampleResponse)
+			assert.Equal(t, tt.passed, report.Passed)
+			assert.Equal(t, tt.passed, report.Results[0].Passed)
+			assert.Equal(t, tt.explanation, report.Results[0].Explanation)
+		})
+	}
+}
+
+func TestEvaluate_PassesOnlyWhenAllRulesPass(t *testing.T) {
+	report := Evaluate(Policy{Rules: []PolicyRule{
+		{Name: "title", Field: "Title", Op: OpNotEmpty},
+		{Field: "InaccessibleLinks", Op: OpEqual, Value: 0},
+	}}, sampleResponse)
+
+	assert.False(t, report.Passed)
+	assert.Equal(t, "title", report.Results[0].Name)
+	assert.Equal(t, "InaccessibleLinks == 0", report.Results[1].Name)
+	assert.Equal(t, 2.0, report.Results[1].Actual)
+}
+
+func TestParse_YAMLAndJSON(t *testing.T) {
+	yamlPolicy := `
+rules:
+  - name: no broken links
+    field: InaccessibleLinks
+    op: "<="
+    value: 0
+  - field: Title
+    op: notEmpty
+`
+	jsonPolicy := `{"rules": [{"name": "no broken links", "field": "InaccessibleLinks", "op": "<=", "value": 0}, {"field": "Title", "op": "notEmpty"}]}`
+
+	for _, data := range []string{yamlPolicy, jsonPolicy} {
+		policy, err := Parse([]byte(data))
+		assert.NoError(t, err)
+		assert.Len(t, policy.Rules, 2)
+		assert.Equal(t, "no broken links", policy.Rules[0].Name)
+		assert.False(t, Evaluate(policy, sampleResponse).Passed)
+	}
+}
+
+func TestParse_InvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{"unknown op", `rules: [{field: Title, op: "~="}]`, `invalid policy: rule 1 has unknown op "~="`},
+		{"missing field", `rules: [{op: empty}]`, "invalid policy: rule 1 has no field"},
+		{"bad pattern", `rules: [{field: Title, op: matches, value: "("}]`, "invalid policy: rule 1: error parsing regexp"},
+		{"unknown key", `rules: [{field: Title, op: empty, severity: high}]`, "invalid policy: yaml: unmarshal errors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.policy))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package render
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{
+	"url", "finalUrl", "error", "htmlVersion", "title", "h1", "h2", "h3", "h4", "h5", "h6",
+	"internalLinks", "externalLinks", "inaccessibleLinks", "inaccessibleInternalLinks", "inaccessibleExternalLinks",
+	"hasLoginForm", "timedOut", "warnings", "findings", "policyPassed", "errors",
+}
+
+// WriteCSV writes one flat row per analysis. Cells that do not apply, such
+// as the counts of a failed analysis, are left empty, and cells taken from
+// the page cannot turn into spreadsheet formulas.
+func WriteCSV(w io.Writer, analyses []Analysis) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, analysis := range analyses {
+		if err := writer.Write(escapeFormulas(csvRow(analysis))); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvRow(analysis Analysis) []string {
+	row := make([]string, len(csvHeader))
+	row[0] = analysis.URL
+	result := analysis.Result
+	if result == nil {
+		row[2] = analysis.Error
+		return row
+	}
+
+	row[1] = result.FinalURL
+	row[3] = result.HTMLVersion
+	row[4] = result.Title
+	for level := 1; level <= 6; level++ {
+		row[4+level] = strconv.Itoa(result.HeadingCounts["h"+strconv.Itoa(level)])
+	}
+	row[11] = strconv.Itoa(result.InternalLinks)
+	row[12] = strconv.Itoa(result.ExternalLinks)
+	row[13] = strconv.Itoa(result.InaccessibleLinks)
+	row[14] = strconv.Itoa(result.InaccessibleInternalLinks)
+	row[15] = strconv.Itoa(result.InaccessibleExternalLinks)
+	row[16] = strconv.FormatBool(result.HasLoginForm)
+	row[17] = strconv.FormatBool(result.TimedOut)
+	row[18] = strconv.Itoa(len(result.Warnings))
+	row[19] = strconv.Itoa(len(Findings(*result)))
+	if result.Policy != nil {
+		row[20] = strconv.FormatBool(result.Policy.Passed)
+	}
+	row[21] = strconv.Itoa(len(result.Errors))
+	return row
+}
+
+// escapeFormulas prefixes a quote to the cells a spreadsheet would evaluate
+// as a formula, such as a page title of =HYPERLINK(...).
+func escapeFormulas(row []string) []string {
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			row[i] = "'" + cell
+		}
+	}
+	return row
+}
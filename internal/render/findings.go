@@ -0,0 +1,327 @@
+package render
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/naskavinda/webpageanalyzer/internal/analyzer"
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// Analysis is one analyzed page as handed to the encoders: either a result
+// or the error, and its code, that prevented it.
+type Analysis struct {
+	URL    string
+	Result *PageAnalysisResponse
+	Error  string
+	Code   string
+}
+
+// Finding levels, named as in SARIF.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Rule describes a kind of finding.
+type Rule struct {
+	ID          string
+	Description string
+	Level       string
+}
+
+// Finding is a problem found on an analyzed page. Line and Column point into
+// the page source when known.
+type Finding struct {
+	RuleID  string
+	Level   string
+	Message string
+	URL     string
+	Line    int
+	Column  int
+}
+
+const (
+	RuleBrokenLink        = "broken-link"
+	RuleInaccessibleLinks = "inaccessible-links"
+	RuleMissingTitle      = "missing-title"
+	RuleMissingH1         = "missing-h1"
+	RuleMultipleH1        = "multiple-h1"
+	RuleSkippedHeading    = "skipped-heading-level"
+	RuleEmptyHeading      = "empty-heading"
+	RuleHiddenHeading     = "hidden-heading"
+	RuleSEOTitle          = "seo-title"
+	RuleSEODescription    = "seo-description"
+	RuleSEONotIndexable   = "seo-not-indexable"
+	RuleSEOCanonical      = "seo-canonical"
+	RuleSEOMetaRefresh    = "seo-meta-refresh"
+	RuleSEOViewport       = "seo-missing-viewport"
+	RuleLoginFormOverHTTP = "login-form-over-http"
+	RuleFormInsecure      = "form-insecure-action"
+	RuleFormCredentialURL = "form-credentials-in-url"
+	RuleFormCrossOrigin   = "form-cross-origin"
+	RuleFormMissingCSRF   = "form-missing-csrf-token"
+	RuleFormAutocomplete  = "form-missing-autocomplete"
+	RuleFormMissingLabel  = "form-missing-label"
+	RuleFormFileEncoding  = "form-file-encoding"
+	RuleHTTPSDowngrade    = "https-downgrade"
+	RuleRedirectProblem   = "redirect-problem"
+	RuleAnalysisTimedOut  = "analysis-timed-out"
+	RuleAnalysisWarning   = "analysis-warning"
+	RuleCheckIncomplete   = "check-incomplete"
+	RulePolicy            = "policy"
+)
+
+// Rules lists every rule a finding may refer to, in report order.
+var Rules = []Rule{
+	{RuleBrokenLink, "A link on the page could not be reached.", LevelError},
+	{RuleInaccessibleLinks, "Links on the page could not be reached; request includeLinks for details.", LevelError},
+	{RuleMissingTitle, "The page has no title.", LevelWarning},
+	{RuleMissingH1, "The page has no h1 heading.", LevelWarning},
+	{RuleMultipleH1, "The page has more than one h1 heading.", LevelNote},
+	{RuleSkippedHeading, "A heading skips a level of the outline, e.g. an h4 after an h2.", LevelWarning},
+	{RuleEmptyHeading, "A heading has no text.", LevelWarning},
+	{RuleHiddenHeading, "A heading is hidden from readers with aria-hidden, hidden or display:none.", LevelNote},
+	{RuleSEOTitle, "The title is too short, too long or given more than once.", LevelWarning},
+	{RuleSEODescription, "The meta description is missing, too short, too long, repeated or the same as the title.", LevelWarning},
+	{RuleSEONotIndexable, "Search engines are asked not to index the page.", LevelNote},
+	{RuleSEOCanonical, "The page has more than one canonical link.", LevelWarning},
+	{RuleSEOMetaRefresh, "The page reloads or redirects with a meta refresh.", LevelWarning},
+	{RuleSEOViewport, "The page has no viewport meta tag.", LevelNote},
+	{RuleLoginFormOverHTTP, "A login form is served or submitted over plain HTTP.", LevelError},
+	{RuleFormInsecure, "A form posts or sends sensitive fields over plain HTTP.", LevelError},
+	{RuleFormCredentialURL, "A form sends a password in the URL with GET.", LevelError},
+	{RuleFormCrossOrigin, "A form submits to another origin.", LevelNote},
+	{RuleFormMissingCSRF, "A form posts to its own origin without an anti-forgery token.", LevelWarning},
+	{RuleFormAutocomplete, "A password or card field has no matching autocomplete token.", LevelNote},
+	{RuleFormMissingLabel, "A form field has no label.", LevelWarning},
+	{RuleFormFileEncoding, "A form with a file field is not sent as multipart/form-data.", LevelWarning},
+	{RuleHTTPSDowngrade, "A redirect leads from HTTPS to HTTP.", LevelWarning},
+	{RuleRedirectProblem, "A redirect chain loops or is too long.", LevelError},
+	{RuleAnalysisTimedOut, "The analysis did not finish in time; results are partial.", LevelWarning},
+	{RuleAnalysisWarning, "The analyzer reported a problem with the page.", LevelNote},
+	{RuleCheckIncomplete, "A check failed or did not finish; its results are missing or partial.", LevelError},
+	{RulePolicy, "A policy rule failed.", LevelError},
+}
+
+// Findings lists the problems found in a successful analysis.
+func Findings(result PageAnalysisResponse) []Finding {
+	pageURL := result.FinalURL
+	if pageURL == "" {
+		pageURL = result.URL
+	}
+	var findings []Finding
+	add := func(ruleID string, level string, message string) {
+		findings = append(findings, Finding{RuleID: ruleID, Level: level, Message: message, URL: pageURL})
+	}
+
+	if result.Links != nil {
+		for _, link := range result.Links {
+			if analyzer.IsInaccessible(link) {
+				findings = append(findings, Finding{
+					RuleID:  RuleBrokenLink,
+					Level:   LevelError,
+					Message: linkMessage(link),
+					URL:     pageURL,
+					Line:    link.Line,
+					Column:  link.Column,
+				})
+			}
+		}
+	} else if result.InaccessibleLinks > 0 {
+		add(RuleInaccessibleLinks, LevelError, fmt.Sprintf("%d link(s) could not be reached", result.InaccessibleLinks))
+	}
+
+	if result.Title == "" && checkRan(result, analyzer.CheckTitle) {
+		add(RuleMissingTitle, LevelWarning, "the page has no title")
+	}
+	for _, issue := range result.HeadingIssues {
+		if rule, ok := headingIssueRules[issue.Code]; ok {
+			add(rule, ruleLevel(rule), issue.Message)
+		}
+	}
+	if result.SEO != nil {
+		for _, issue := range result.SEO.Issues {
+			// A missing title is reported from the title above.
+			if rule, ok := seoIssueRules[issue.Code]; ok {
+				add(rule, ruleLevel(rule), issue.Message)
+			}
+		}
+	}
+	servedOverHTTP := false
+	if parsed, err := url.Parse(pageURL); err == nil && parsed.Scheme == "http" {
+		servedOverHTTP = true
+	}
+	if result.HasLoginForm && servedOverHTTP {
+		add(RuleLoginFormOverHTTP, LevelError, "the page has a login form but is served over http")
+	}
+	for _, form := range result.Forms {
+		for _, issue := range form.Issues {
+			rule := formIssueRules[issue.Code]
+			if issue.Code == FormIssueInsecureAction && form.Kind == FormLogin {
+				if servedOverHTTP {
+					// Already reported for the whole page.
+					continue
+				}
+				rule = RuleLoginFormOverHTTP
+			}
+			if rule == "" {
+				continue
+			}
+			add(rule, ruleLevel(rule), fmt.Sprintf("%s %d %s", formLabel(form.Kind), form.Index+1, issue.Message))
+		}
+	}
+	if chain := result.Redirects; chain != nil {
+		if chain.HTTPSDowngrade {
+			add(RuleHTTPSDowngrade, LevelWarning, fmt.Sprintf("%s redirects from https to http", result.URL))
+		}
+		if chain.Loop || chain.TooManyHops {
+			add(RuleRedirectProblem, LevelError, fmt.Sprintf("%s redirects in a loop or too many times", result.URL))
+		}
+	}
+	if result.TimedOut {
+		add(RuleAnalysisTimedOut, LevelWarning, "the analysis did not finish in time; results are partial")
+	}
+	for _, warning := range result.Warnings {
+		add(RuleAnalysisWarning, LevelNote, issueMessage(warning))
+	}
+	for _, issue := range result.Errors {
+		level := LevelError
+		if issue.Code != IssueCheckFailed {
+			// Timed out and skipped checks are already reported as a timeout.
+			level = LevelWarning
+		}
+		add(RuleCheckIncomplete, level, issueMessage(issue))
+	}
+	if result.Policy != nil {
+		for _, rule := range result.Policy.Results {
+			if !rule.Passed {
+				add(RulePolicy, LevelError, fmt.Sprintf("policy rule %q failed: %s", rule.Name, rule.Explanation))
+			}
+		}
+	}
+	return findings
+}
+
+func linkMessage(link LinkDetail) string {
+	switch {
+	case link.StatusCode != 0:
+		return fmt.Sprintf("link %s is %s (HTTP %d)", link.URL, link.Status, link.StatusCode)
+	case link.URL == "":
+		return fmt.Sprintf("link %q is %s", link.Href, link.Status)
+	case link.ErrorKind != "":
+		return fmt.Sprintf("link %s is %s (%s)", link.URL, link.Status, link.ErrorKind)
+	}
+	return fmt.Sprintf("link %s is %s", link.URL, link.Status)
+}
+
+func issueMessage(issue Issue) string {
+	if issue.Check == "" {
+		return issue.Message
+	}
+	return fmt.Sprintf("%s check: %s", issue.Check, issue.Message)
+}
+
+// ruleChecks maps the rules that are only checked by one check to it.
+var ruleChecks = map[string]string{
+	RuleBrokenLink:        analyzer.CheckLinks,
+	RuleInaccessibleLinks: analyzer.CheckLinks,
+	RuleMissingTitle:      analyzer.CheckTitle,
+	RuleMissingH1:         analyzer.CheckHeadings,
+	RuleMultipleH1:        analyzer.CheckHeadings,
+	RuleSkippedHeading:    analyzer.CheckHeadings,
+	RuleEmptyHeading:      analyzer.CheckHeadings,
+	RuleHiddenHeading:     analyzer.CheckHeadings,
+	RuleSEOTitle:          analyzer.CheckSEO,
+	RuleSEODescription:    analyzer.CheckSEO,
+	RuleSEONotIndexable:   analyzer.CheckSEO,
+	RuleSEOCanonical:      analyzer.CheckSEO,
+	RuleSEOMetaRefresh:    analyzer.CheckSEO,
+	RuleSEOViewport:       analyzer.CheckSEO,
+	RuleLoginFormOverHTTP: analyzer.CheckLoginForm,
+	RuleFormInsecure:      analyzer.CheckForms,
+	RuleFormCredentialURL: analyzer.CheckForms,
+	RuleFormCrossOrigin:   analyzer.CheckForms,
+	RuleFormMissingCSRF:   analyzer.CheckForms,
+	RuleFormAutocomplete:  analyzer.CheckForms,
+	RuleFormMissingLabel:  analyzer.CheckForms,
+	RuleFormFileEncoding:  analyzer.CheckForms,
+}
+
+// checkRan reports whether check completed. Results that do not list their
+// checks count as complete.
+func checkRan(result PageAnalysisResponse, check string) bool {
+	return result.Checks == nil || slices.Contains(result.Checks, check)
+}
+
+// ruleChecked reports whether the check behind rule completed, so that a
+// rule without findings passed rather than went unchecked.
+func ruleChecked(result PageAnalysisResponse, id string) bool {
+	check, ok := ruleChecks[id]
+	return !ok || checkRan(result, check)
+}
+
+// headingIssueRules maps the heading issue codes that have a rule of their
+// own to it.
+var headingIssueRules = map[string]string{
+	HeadingIssueMissingH1:    RuleMissingH1,
+	HeadingIssueMultipleH1:   RuleMultipleH1,
+	HeadingIssueSkippedLevel: RuleSkippedHeading,
+	HeadingIssueEmpty:        RuleEmptyHeading,
+	HeadingIssueHidden:       RuleHiddenHeading,
+}
+
+// seoIssueRules maps the SEO issue codes that have a rule of their own to
+// it.
+var seoIssueRules = map[string]string{
+	SEOIssueTitleTooShort:        RuleSEOTitle,
+	SEOIssueTitleTooLong:         RuleSEOTitle,
+	SEOIssueMultipleTitles:       RuleSEOTitle,
+	SEOIssueMissingDescription:   RuleSEODescription,
+	SEOIssueDescriptionTooShort:  RuleSEODescription,
+	SEOIssueDescriptionTooLong:   RuleSEODescription,
+	SEOIssueMultipleDescriptions: RuleSEODescription,
+	SEOIssueDuplicateDescription: RuleSEODescription,
+	SEOIssueNotIndexable:         RuleSEONotIndexable,
+	SEOIssueMultipleCanonicals:   RuleSEOCanonical,
+	SEOIssueMetaRefresh:          RuleSEOMetaRefresh,
+	SEOIssueMissingViewport:      RuleSEOViewport,
+}
+
+// formIssueRules maps the issue codes of forms to their rule.
+var formIssueRules = map[string]string{
+	FormIssueInsecureAction:       RuleFormInsecure,
+	FormIssueCredentialsInURL:     RuleFormCredentialURL,
+	FormIssueCrossOrigin:          RuleFormCrossOrigin,
+	FormIssueMissingCSRFToken:     RuleFormMissingCSRF,
+	FormIssueMissingAutocomplete:  RuleFormAutocomplete,
+	FormIssueMissingLabel:         RuleFormMissingLabel,
+	FormIssueFileWithoutMultipart: RuleFormFileEncoding,
+}
+
+// formLabel names a form of kind in messages, e.g. "login form".
+func formLabel(kind string) string {
+	if kind == "" || kind == FormOther {
+		return "form"
+	}
+	return strings.ReplaceAll(kind, "_", " ") + " form"
+}
+
+func ruleLevel(id string) string {
+	if i := ruleIndex(id); i >= 0 {
+		return Rules[i].Level
+	}
+	return LevelWarning
+}
+
+func ruleIndex(id string) int {
+	for i, rule := range Rules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,135 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatSARIF    = "sarif"
+	FormatJUnit    = "junit"
+)
+
+// Encoder writes analyses in one output format.
+type Encoder func(w io.Writer, analyses []Analysis) error
+
+// Encoders holds the formats rendered by this package; JSON is written by
+// the callers in their own shape.
+var Encoders = map[string]Encoder{
+	FormatYAML:     WriteYAML,
+	FormatCSV:      WriteCSV,
+	FormatMarkdown: WriteMarkdown,
+	FormatHTML:     WriteHTML,
+	FormatSARIF:    WriteSARIF,
+	FormatJUnit:    WriteJUnit,
+}
+
+// ContentTypes maps each format to the media type it is served as.
+var ContentTypes = map[string]string{
+	FormatJSON:     "application/json",
+	FormatYAML:     "application/yaml",
+	FormatCSV:      "text/csv; charset=utf-8",
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatHTML:     "text/html; charset=utf-8",
+	FormatSARIF:    "application/sarif+json",
+	FormatJUnit:    "application/xml",
+}
+
+// acceptedTypes maps media types a client may ask for to formats.
+var acceptedTypes = map[string]string{
+	"application/json":       FormatJSON,
+	"application/yaml":       FormatYAML,
+	"application/x-yaml":     FormatYAML,
+	"text/yaml":              FormatYAML,
+	"text/csv":               FormatCSV,
+	"text/markdown":          FormatMarkdown,
+	"text/x-markdown":        FormatMarkdown,
+	"text/html":              FormatHTML,
+	"application/sarif+json": FormatSARIF,
+	"application/sarif":      FormatSARIF,
+	"application/junit+xml":  FormatJUnit,
+	"application/xml":        FormatJUnit,
+	"text/xml":               FormatJUnit,
+}
+
+// formatAliases are the names accepted by FormatForName besides the format
+// names themselves.
+var formatAliases = map[string]string{
+	"yml": FormatYAML,
+	"md":  FormatMarkdown,
+	"xml": FormatJUnit,
+}
+
+// FormatForName resolves a format given by name, as in ?format= or a
+// command-line flag.
+func FormatForName(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := formatAliases[name]; ok {
+		return alias, true
+	}
+	if _, ok := ContentTypes[name]; !ok {
+		return "", false
+	}
+	return name, true
+}
+
+// Write renders analyses in format with its Encoder.
+func Write(w io.Writer, format string, analyses []Analysis) error {
+	encoder, ok := Encoders[format]
+	if !ok {
+		return fmt.Errorf("unknown format: %s", format)
+	}
+	return encoder(w, analyses)
+}
+
+// FormatForAccept picks the preferred supported format of an Accept header.
+// A missing header or a wildcard means JSON; false means nothing offered is
+// supported.
+func FormatForAccept(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return FormatJSON, true
+	}
+
+	type candidate struct {
+		mediaType string
+		quality   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{mediaType, quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if format, ok := acceptedTypes[c.mediaType]; ok {
+			return format, true
+		}
+		if c.mediaType == "*/*" || c.mediaType == "application/*" {
+			return FormatJSON, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"html/template"
+	"io"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// WriteHTML writes a self-contained HTML report with inline styles and no
+// external resources.
+func WriteHTML(w io.Writer, analyses []Analysis) error {
+	pages := make([]htmlPage, 0, len(analyses))
+	for _, analysis := range analyses {
+		page := htmlPage{URL: analysis.URL, Error: analysis.Error}
+		if analysis.Result != nil {
+			page.Rows = summaryRows(*analysis.Result)
+			page.Findings = Findings(*analysis.Result)
+			page.Policy = analysis.Result.Policy
+		}
+		pages = append(pages, page)
+	}
+	return htmlReport.Execute(w, pages)
+}
+
+type htmlPage struct {
+	URL      string
+	Error    string
+	Rows     []summaryRow
+	Findings []Finding
+	Policy   *PolicyReport
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"findingText": findingText,
+	"passFail":    passFail,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Web page analysis</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+section { margin-bottom: 2rem; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.3rem 0.8rem; border-bottom: 1px solid #ddd; }
+.error { color: #b00020; }
+.warning { color: #a15c00; }
+.note { color: #555; }
+.FAIL { color: #b00020; font-weight: bold; }
+.PASS { color: #1b7f3b; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Web page analysis</h1>
+{{- range .}}
+<section>
+<h2>{{.URL}}</h2>
+{{- if .Error}}
+<p class="error">Error: {{.Error}}</p>
+{{- else}}
+<table>
+{{- range .Rows}}
+<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- if .Findings}}
+<h3>Findings</h3>
+<ul>
+{{- range .Findings}}
+<li class="{{.Level}}"><strong>{{.Level}}</strong> <code>{{.RuleID}}</code>: {{findingText .}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No findings.</p>
+{{- end}}
+{{- with .Policy}}
+<h3>Policy</h3>
+<ul>
+{{- range .Results}}
+<li><span class="{{passFail .Passed}}">{{passFail .Passed}}</span> {{.Name}}: {{.Explanation}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,33 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// WriteJSON writes one JSON line per analysis, shaped like the JSON body of
+// POST /analyzer.
+func WriteJSON(w io.Writer, analyses []Analysis) error {
+	encoder := json.NewEncoder(w)
+	for _, analysis := range analyses {
+		if err := encoder.Encode(analysisBody(analysis)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analysisBody is the body of POST /analyzer for analysis: {url, content},
+// or {url, error, code} when the page could not be analyzed.
+func analysisBody(analysis Analysis) any {
+	if analysis.Result != nil {
+		return AnalyzerResponse{URL: analysis.URL, Content: *analysis.Result}
+	}
+	body := map[string]string{"url": analysis.URL, "error": analysis.Error}
+	if analysis.Code != "" {
+		body["code"] = analysis.Code
+	}
+	return body
+}
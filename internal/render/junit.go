@@ -0,0 +1,155 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// analysisTestCase is the test case telling whether the page could be
+// analyzed at all.
+const analysisTestCase = "analysis"
+
+// WriteJUnit writes analyses as JUnit XML: one test suite per page with a
+// test case per rule, failed by its error and warning findings and skipped
+// when the check behind it did not run, plus one per policy rule.
+func WriteJUnit(w io.Writer, analyses []Analysis) error {
+	report := junitTestSuites{Name: ToolName}
+	for _, analysis := range analyses {
+		suite := junitSuite(analysis)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSuite(analysis Analysis) junitTestSuite {
+	suite := junitTestSuite{Name: analysis.URL}
+	addCase := func(testCase junitTestCase) {
+		testCase.ClassName = analysis.URL
+		suite.Tests++
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+		if testCase.Error != nil {
+			suite.Errors++
+		}
+		if testCase.Skipped != nil {
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if analysis.Result == nil {
+		addCase(junitTestCase{
+			Name:  analysisTestCase,
+			Error: &junitProblem{Message: analysis.Error, Type: "error", Text: analysis.Error},
+		})
+		return suite
+	}
+	addCase(junitTestCase{Name: analysisTestCase})
+
+	byRule := make(map[string][]Finding)
+	for _, finding := range Findings(*analysis.Result) {
+		byRule[finding.RuleID] = append(byRule[finding.RuleID], finding)
+	}
+	for _, rule := range Rules {
+		if rule.ID == RulePolicy {
+			continue
+		}
+		if len(byRule[rule.ID]) == 0 && !ruleChecked(*analysis.Result, rule.ID) {
+			addCase(junitTestCase{
+				Name:    rule.ID,
+				Skipped: &junitSkipped{Message: "the " + ruleChecks[rule.ID] + " check did not run"},
+			})
+			continue
+		}
+		addCase(junitRuleCase(rule, byRule[rule.ID]))
+	}
+	if analysis.Result.Policy != nil {
+		for _, result := range analysis.Result.Policy.Results {
+			testCase := junitTestCase{Name: "policy: " + result.Name}
+			if !result.Passed {
+				testCase.Failure = &junitProblem{Message: result.Explanation, Type: RulePolicy, Text: result.Explanation}
+			} else {
+				testCase.SystemOut = result.Explanation
+			}
+			addCase(testCase)
+		}
+	}
+	return suite
+}
+
+func junitRuleCase(rule Rule, findings []Finding) junitTestCase {
+	testCase := junitTestCase{Name: rule.ID}
+	var failures, notes []string
+	for _, finding := range findings {
+		text := findingText(finding)
+		if finding.Level == LevelNote {
+			notes = append(notes, text)
+		} else {
+			failures = append(failures, text)
+		}
+	}
+	if len(failures) > 0 {
+		testCase.Failure = &junitProblem{
+			Message: fmt.Sprintf("%d finding(s): %s", len(failures), rule.Description),
+			Type:    rule.ID,
+			Text:    strings.Join(failures, "\n"),
+		}
+	}
+	testCase.SystemOut = strings.Join(notes, "\n")
+	return testCase
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes a summary meant to be pasted into pull request
+// comments: a table per page followed by its findings and policy results.
+func WriteMarkdown(w io.Writer, analyses []Analysis) error {
+	var b strings.Builder
+	b.WriteString("## Web page analysis\n")
+	for _, analysis := range analyses {
+		fmt.Fprintf(&b, "\n### %s\n\n", markdownText(analysis.URL))
+		if analysis.Result == nil {
+			fmt.Fprintf(&b, "**Error:** %s\n", markdownText(analysis.Error))
+			continue
+		}
+
+		b.WriteString("| Check | Result |\n| --- | --- |\n")
+		for _, row := range summaryRows(*analysis.Result) {
+			fmt.Fprintf(&b, "| %s | %s |\n", row.Label, markdownCell(row.Value))
+		}
+
+		findings := Findings(*analysis.Result)
+		if len(findings) == 0 {
+			b.WriteString("\nNo findings.\n")
+		} else {
+			b.WriteString("\n**Findings**\n\n")
+			for _, finding := range findings {
+				fmt.Fprintf(&b, "- **%s** `%s`: %s\n", finding.Level, finding.RuleID, markdownText(findingText(finding)))
+			}
+		}
+
+		if policy := analysis.Result.Policy; policy != nil {
+			b.WriteString("\n**Policy**\n\n")
+			for _, rule := range policy.Results {
+				fmt.Fprintf(&b, "- %s %s: %s\n", passFail(rule.Passed), markdownText(rule.Name), markdownText(rule.Explanation))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;", "\n", " ",
+)
+
+func markdownText(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+func markdownCell(text string) string {
+	return strings.ReplaceAll(markdownText(text), "|", `\|`)
+}
@@ -0,0 +1,456 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var brokenPage = PageAnalysisResponse{
+	URL:               "http://example.com",
+	FinalURL:          "http://example.com/",
+	HeadingCounts:     map[string]int{"h1": 2},
+	HeadingIssues:     []Issue{{Code: HeadingIssueMultipleH1, Message: "the page has 2 h1 headings"}},
+	InaccessibleLinks: 1,
+	HasLoginForm:      true,
+	Links: []LinkDetail{
+		{URL: "http://example.com/ok", Status: LinkStatusOK, Line: 3, Column: 5},
+		{URL: "http://example.com/gone", Status: LinkStatusBroken, StatusCode: 404, Line: 4, Column: 7},
+	},
+	Warnings: []Issue{{Code: IssueBodyTruncated, Message: "page is larger than 10 bytes"}},
+	Policy: &PolicyReport{Results: []PolicyRuleResult{
+		{Name: "has title", Passed: false, Explanation: "Title is missing"},
+		{Name: "few links", Passed: true, Explanation: "Links is 2 item(s)"},
+	}},
+}
+
+func TestFindings(t *testing.T) {
+	findings := Findings(brokenPage)
+
+	assert.Equal(t, []Finding{
+		{RuleID: RuleBrokenLink, Level: LevelError, Message: "link http://example.com/gone is broken (HTTP 404)", URL: "http://example.com/", Line: 4, Column: 7},
+		{RuleID: RuleMissingTitle, Level: LevelWarning, Message: "the page has no title", URL: "http://example.com/"},
+		{RuleID: RuleMultipleH1, Level: LevelNote, Message: "the page has 2 h1 headings", URL: "http://example.com/"},
+		{RuleID: RuleLoginFormOverHTTP, Level: LevelError, Message: "the page has a login form but is served over http", URL: "http://example.com/"},
+		{RuleID: RuleAnalysisWarning, Level: LevelNote, Message: "page is larger than 10 bytes", URL: "http://example.com/"},
+		{RuleID: RulePolicy, Level: LevelError, Message: `policy rule "has title" failed: Title is missing`, URL: "http://example.com/"},
+	}, findings)
+}
+
+func TestFindings_WithoutLinkDetails(t *testing.T) {
+	findings := Findings(PageAnalysisResponse{
+		URL:               "https://example.com",
+		Title:             "Fine",
+		HeadingCounts:     map[string]int{},
+		HeadingIssues:     []Issue{{Code: HeadingIssueMissingH1, Message: "the page has no h1 heading"}},
+		InaccessibleLinks: 3,
+	})
+
+	assert.Equal(t, []Finding{
+		{RuleID: RuleInaccessibleLinks, Level: LevelError, Message: "3 link(s) could not be reached", URL: "https://example.com"},
+		{RuleID: RuleMissingH1, Level: LevelWarning, Message: "the page has no h1 heading", URL: "https://example.com"},
+	}, findings)
+}
+
+func TestFindings_SkipsChecksThatDidNotRun(t *testing.T) {
+	findings := Findings(PageAnalysisResponse{
+		URL:           "https://example.com",
+		HeadingCounts: map[string]int{},
+		Checks:        []string{"htmlVersion", "links"},
+	})
+
+	assert.Empty(t, findings)
+}
+
+func TestFindings_HeadingIssues(t *testing.T) {
+	findings := Findings(PageAnalysisResponse{
+		URL:           "https://example.com",
+		Title:         "Fine",
+		HeadingCounts: map[string]int{"h1": 2, "h2": 1, "h4": 1},
+		HeadingIssues: []Issue{
+			{Code: HeadingIssueMultipleH1, Message: "the page has 2 h1 headings"},
+			{Code: HeadingIssueSkippedLevel, Message: `heading 4 (h4 "Flags") follows an h2, skipping h3`},
+			{Code: HeadingIssueEmpty, Message: "heading 5 (h2) is empty"},
+			{Code: HeadingIssueHidden, Message: `heading 6 (h2 "Menu") is hidden by aria-hidden`},
+		},
+	})
+
+	assert.Equal(t, []Finding{
+		{RuleID: RuleMultipleH1, Level: LevelNote, Message: "the page has 2 h1 headings", URL: "https://example.com"},
+		{RuleID: RuleSkippedHeading, Level: LevelWarning, Message: `heading 4 (h4 "Flags") follows an h2, skipping h3`, URL: "https://example.com"},
+		{RuleID: RuleEmptyHeading, Level: LevelWarning, Message: "heading 5 (h2) is empty", URL: "https://example.com"},
+		{RuleID: RuleHiddenHeading, Level: LevelNote, Message: `heading 6 (h2 "Menu") is hidden by aria-hidden`, URL: "https://example.com"},
+	}, findings)
+}
+
+func TestFindings_SEOIssues(t *testing.T) {
+	findings := Findings(PageAnalysisResponse{
+		URL:           "https://example.com",
+		HeadingCounts: map[string]int{"h1": 1},
+		SEO: &SEO{Issues: []Issue{
+			{Code: SEOIssueMissingTitle, Message: "the page has no title"},
+			{Code: SEOIssueMissingDescription, Message: "the page has no meta description"},
+			{Code: SEOIssueNotIndexable, Message: "search engines are asked not to index the page"},
+		}},
+	})
+
+	assert.Equal(t, []Finding{
+		{RuleID: RuleMissingTitle, Level: LevelWarning, Message: "the page has no title", URL: "https://example.com"},
+		{RuleID: RuleSEODescription, Level: LevelWarning, Message: "the page has no meta description", URL: "https://example.com"},
+		{RuleID: RuleSEONotIndexable, Level: LevelNote, Message: "search engines are asked not to index the page", URL: "https://example.com"},
+	}, findings)
+}
+
+func TestFindings_FormIssues(t *testing.T) {
+	findings := Findings(PageAnalysisResponse{
+		URL:           "https://example.com",
+		Title:         "Fine",
+		HeadingCounts: map[string]int{"h1": 1},
+		HasLoginForm:  true,
+		Forms: []Form{
+			{Index: 0, Kind: FormSearch, Action: "https://example.com/search", Issues: []Issue{
+				{Code: FormIssueMissingLabel, Message: "field(s) without a label: q"},
+			}},
+			{Index: 1, Kind: FormLogin, Action: "http://example.com/session", Issues: []Issue{
+				{Code: FormIssueInsecureAction, Message: "submits to http://example.com/session without https"},
+			}},
+			{Index: 2, Kind: FormOther, Action: "https://example.com/comments", Issues: []Issue{
+				{Code: FormIssueMissingCSRFToken, Message: "posts without a hidden anti-forgery token"},
+			}},
+		},
+	})
+
+	assert.Equal(t, []Finding{
+		{RuleID: RuleFormMissingLabel, Level: LevelWarning, Message: "search form 1 field(s) without a label: q", URL: "https://example.com"},
+		{RuleID: RuleLoginFormOverHTTP, Level: LevelError, Message: "login form 2 submits to http://example.com/session without https", URL: "https://example.com"},
+		{RuleID: RuleFormMissingCSRF, Level: LevelWarning, Message: "form 3 posts without a hidden anti-forgery token", URL: "https://example.com"},
+	}, findings)
+}
+
+func TestFindings_IncompleteChecks(t *testing.T) {
+	findings := Findings(PageAnalysisResponse{
+		URL:           "https://example.com",
+		Title:         "Partial",
+		HeadingCounts: map[string]int{"h1": 1},
+		Warnings:      []Issue{{Code: IssueLinksUnchecked, Check: "links", Message: "2 link(s) were not checked before the analysis stopped"}},
+		Errors: []Issue{
+			{Code: IssueCheckFailed, Check: "wordCount", Message: "boom"},
+			{Code: IssueCheckSkipped, Check: "loginForm", Message: "the check was skipped because the analysis timed out"},
+		},
+	})
+
+	assert.Equal(t, []Finding{
+		{RuleID: RuleAnalysisWarning, Level: LevelNote, Message: "links check: 2 link(s) were not checked before the analysis stopped", URL: "https://example.com"},
+		{RuleID: RuleCheckIncomplete, Level: LevelError, Message: "wordCount check: boom", URL: "https://example.com"},
+		{RuleID: RuleCheckIncomplete, Level: LevelWarning, Message: "loginForm check: the check was skipped because the analysis timed out", URL: "https://example.com"},
+	}, findings)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteSARIF(&out, []Analysis{
+		{URL: "http://example.com", Result: &brokenPage},
+		{URL: "https://down.example.com", Error: "failed to fetch the webpage"},
+	})
+	assert.NoError(t, err)
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Invocations []struct {
+				ExecutionSuccessful        bool `json:"executionSuccessful"`
+				ToolExecutionNotifications []struct {
+					Message struct {
+						Text string `json:"text"`
+					} `json:"message"`
+				} `json:"toolExecutionNotifications"`
+			} `json:"invocations"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	run := log.Runs[0]
+	assert.Equal(t, ToolName, run.Tool.Driver.Name)
+	assert.Len(t, run.Tool.Driver.Rules, len(Rules))
+	assert.False(t, run.Invocations[0].ExecutionSuccessful)
+	assert.Equal(t, "failed to fetch the webpage", run.Invocations[0].ToolExecutionNotifications[0].Message.Text)
+	assert.Len(t, run.Results, 6)
+	first := run.Results[0]
+	assert.Equal(t, RuleBrokenLink, first.RuleID)
+	assert.Equal(t, RuleBrokenLink, run.Tool.Driver.Rules[first.RuleIndex].ID)
+	assert.Equal(t, "http://example.com/", first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 4, first.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 7, first.Locations[0].PhysicalLocation.Region.StartColumn)
+	assert.Nil(t, run.Results[1].Locations[0].PhysicalLocation.Region)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteJUnit(&out, []Analysis{
+		{URL: "http://example.com", Result: &brokenPage},
+		{URL: "https://down.example.com", Error: "failed to fetch the webpage"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `<?xml version="1.0" encoding="UTF-8"?>`)
+
+	var report junitTestSuites
+	assert.NoError(t, xml.Unmarshal(out.Bytes(), &report))
+	assert.Len(t, report.Suites, 2)
+	// The analysis case, one case per rule except policy and one per policy rule.
+	assert.Equal(t, 1+len(Rules)-1+2, report.Suites[0].Tests)
+	assert.Equal(t, 4, report.Suites[0].Failures)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, report.Suites[0].Tests+1, report.Tests)
+
+	cases := make(map[string]junitTestCase)
+	for _, testCase := range report.Suites[0].Cases {
+		cases[testCase.Name] = testCase
+	}
+	assert.Equal(t, "link http://example.com/gone is broken (HTTP 404) (line 4, column 7)", cases[RuleBrokenLink].Failure.Text)
+	assert.Nil(t, cases[RuleMultipleH1].Failure)
+	assert.Equal(t, "the page has 2 h1 headings", cases[RuleMultipleH1].SystemOut)
+	assert.Equal(t, "Title is missing", cases["policy: has title"].Failure.Message)
+	assert.Nil(t, cases["policy: few links"].Failure)
+	assert.Equal(t, "failed to fetch the webpage", report.Suites[1].Cases[0].Error.Message)
+}
+
+func TestWriteJUnit_SkipsRulesOfChecksThatDidNotRun(t *testing.T) {
+	result := PageAnalysisResponse{URL: "https://example.com", HeadingCounts: map[string]int{"h1": 1}, Checks: []string{"headings"}}
+	var out bytes.Buffer
+	assert.NoError(t, WriteJUnit(&out, []Analysis{{URL: "https://example.com", Result: &result}}))
+
+	var report junitTestSuites
+	assert.NoError(t, xml.Unmarshal(out.Bytes(), &report))
+	cases := make(map[string]junitTestCase)
+	for _, testCase := range report.Suites[0].Cases {
+		cases[testCase.Name] = testCase
+	}
+	assert.Equal(t, "the title check did not run", cases[RuleMissingTitle].Skipped.Message)
+	assert.Equal(t, "the links check did not run", cases[RuleBrokenLink].Skipped.Message)
+	assert.Nil(t, cases[RuleMissingH1].Skipped)
+	assert.Nil(t, cases[RuleRedirectProblem].Skipped)
+	assert.Equal(t, 0, report.Suites[0].Failures)
+	assert.Equal(t, len(ruleChecks)-5, report.Suites[0].Skipped)
+}
+
+func TestFormatForAccept(t *testing.T) {
+	tests := []struct {
+		accept string
+		format string
+		ok     bool
+	}{
+		{"", FormatJSON, true},
+		{"*/*", FormatJSON, true},
+		{"application/json", FormatJSON, true},
+		{"application/sarif+json", FormatSARIF, true},
+		{"application/xml", FormatJUnit, true},
+		{"text/html;q=0.9, application/junit+xml", FormatJUnit, true},
+		{"text/html", FormatHTML, true},
+		{"text/markdown", FormatMarkdown, true},
+		{"application/x-yaml", FormatYAML, true},
+		{"text/csv", FormatCSV, true},
+		{"application/json;q=0.5, application/sarif+json", FormatSARIF, true},
+		{"application/sarif+json;q=0, */*;q=0.1", FormatJSON, true},
+		{"image/png", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			format, ok := FormatForAccept(tt.accept)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.format, format)
+		})
+	}
+}
+
+var healthyPage = PageAnalysisResponse{
+	URL:           "https://example.com",
+	FinalURL:      "https://example.com",
+	HTMLVersion:   "HTML5",
+	Title:         "Example | Home",
+	HeadingCounts: map[string]int{"h1": 1, "h2": 2},
+	InternalLinks: 2,
+	ExternalLinks: 1,
+}
+
+var reportAnalyses = []Analysis{
+	{URL: "https://example.com", Result: &healthyPage},
+	{URL: "http://example.com", Result: &brokenPage},
+	{URL: "https://down.example.com", Error: "failed to fetch the webpage"},
+}
+
+func TestWriteYAML(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteYAML(&out, reportAnalyses[:1]))
+
+	assert.Contains(t, out.String(), "url: https://example.com\n")
+	assert.Contains(t, out.String(), "  htmlVersion: HTML5\n")
+	assert.Contains(t, out.String(), "  headingCounts:\n    h1: 1\n    h2: 2\n")
+
+	out.Reset()
+	assert.NoError(t, WriteYAML(&out, reportAnalyses))
+	assert.Equal(t, 2, bytes.Count(out.Bytes(), []byte("\n---\n")))
+	assert.Contains(t, out.String(), "error: failed to fetch the webpage\n")
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	failed := Analysis{URL: "https://down.example.com", Error: "failed to fetch the webpage", Code: "fetch_failed"}
+	assert.NoError(t, WriteJSON(&out, []Analysis{reportAnalyses[0], failed}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	var response AnalyzerResponse
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &response))
+	assert.Equal(t, AnalyzerResponse{URL: "https://example.com", Content: healthyPage}, response)
+	assert.JSONEq(t, `{"url":"https://down.example.com","error":"failed to fetch the webpage","code":"fetch_failed"}`, lines[1])
+}
+
+func TestWriteTable(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteTable(&out, reportAnalyses[0]))
+	assert.Contains(t, out.String(), "Title               Example | Home\n")
+	assert.Contains(t, out.String(), "Headings            h1: 1, h2: 2\n")
+	assert.Contains(t, out.String(), "Has login form      no\n")
+
+	out.Reset()
+	assert.NoError(t, WriteTable(&out, reportAnalyses[2]))
+	assert.Equal(t, "URL    https://down.example.com\nError  failed to fetch the webpage\n\n", out.String())
+}
+
+func TestWriteTable_LinkPositions(t *testing.T) {
+	page := PageAnalysisResponse{URL: "https://example.com", Links: []LinkDetail{
+		{URL: "https://example.com/ok", Status: LinkStatusOK, Line: 2},
+		{URL: "https://example.com/gone", Status: LinkStatusBroken, Line: 3},
+		{URL: "https://example.com/lost", Status: LinkStatusBroken},
+	}}
+	var out bytes.Buffer
+	assert.NoError(t, WriteTable(&out, Analysis{URL: page.URL, Result: &page}))
+
+	assert.NotContains(t, out.String(), "https://example.com/ok")
+	assert.Contains(t, out.String(), "Link broken         https://example.com/gone (line 3)\n")
+	assert.Contains(t, out.String(), "Link broken         https://example.com/lost\n")
+}
+
+func TestWriteTable_Issues(t *testing.T) {
+	page := PageAnalysisResponse{
+		URL:      "https://example.com",
+		Warnings: []Issue{{Code: IssueBodyTruncated, Message: "page is larger than 10 bytes"}},
+		Errors:   []Issue{{Code: IssueCheckFailed, Check: "links", Message: "check panicked"}},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, WriteTable(&out, Analysis{URL: page.URL, Result: &page}))
+
+	assert.Contains(t, out.String(), "Warning             page is larger than 10 bytes\n")
+	assert.Contains(t, out.String(), "Error               links check: check panicked\n")
+}
+
+func TestHeadingSummary(t *testing.T) {
+	assert.Equal(t, "none", HeadingSummary(nil))
+	assert.Equal(t, "h1: 0, h2: 3", HeadingSummary(map[string]int{"h2": 3, "h1": 0}))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteCSV(&out, reportAnalyses))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{
+		"https://example.com", "https://example.com", "", "HTML5", "Example | Home", "1", "2", "0", "0", "0", "0",
+		"2", "1", "0", "0", "0", "false", "false", "0", "0", "", "0",
+	}, records[1])
+	assert.Equal(t, "6", records[2][19])
+	assert.Equal(t, "false", records[2][20])
+	assert.Equal(t, "failed to fetch the webpage", records[3][2])
+	assert.Equal(t, "", records[3][5])
+}
+
+func TestWriteCSV_EscapesFormulas(t *testing.T) {
+	page := PageAnalysisResponse{URL: "https://example.com", Title: `=HYPERLINK("https://evil.example","x")`}
+	analyses := []Analysis{
+		{URL: "https://example.com", Result: &page},
+		{URL: "+cmd", Error: "-1+1"},
+		{URL: "@sum", Error: "\tindented"},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, WriteCSV(&out, analyses))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, `'=HYPERLINK("https://evil.example","x")`, records[1][4])
+	assert.Equal(t, "https://example.com", records[1][0])
+	assert.Equal(t, []string{"'+cmd", "'-1+1"}, []string{records[2][0], records[2][2]})
+	assert.Equal(t, []string{"'@sum", "'\tindented"}, []string{records[3][0], records[3][2]})
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteMarkdown(&out, reportAnalyses))
+	markdown := out.String()
+
+	assert.True(t, strings.HasPrefix(markdown, "## Web page analysis\n\n### https://example.com\n\n| Check | Result |\n| --- | --- |\n"))
+	assert.Contains(t, markdown, "| Title | Example \\| Home |\n")
+	assert.Contains(t, markdown, "| Headings | h1: 1, h2: 2 |\n")
+	assert.Contains(t, markdown, "\nNo findings.\n")
+	assert.Contains(t, markdown, "- **error** `broken-link`: link http://example.com/gone is broken (HTTP 404) (line 4, column 7)\n")
+	assert.Contains(t, markdown, "- FAIL has title: Title is missing\n")
+	assert.Contains(t, markdown, "### https://down.example.com\n\n**Error:** failed to fetch the webpage\n")
+}
+
+func TestWriteHTML(t *testing.T) {
+	var out bytes.Buffer
+	page := healthyPage
+	page.Title = `<script>alert(1)</script>`
+	assert.NoError(t, WriteHTML(&out, []Analysis{{URL: page.URL, Result: &page}, reportAnalyses[1], reportAnalyses[2]}))
+	report := out.String()
+
+	assert.True(t, strings.HasPrefix(report, "<!DOCTYPE html>"))
+	assert.NotContains(t, report, "<script>")
+	assert.NotContains(t, report, "<link")
+	assert.Contains(t, report, "<tr><th>Title</th><td>&lt;script&gt;alert(1)&lt;/script&gt;</td></tr>")
+	assert.Contains(t, report, `<li class="error"><strong>error</strong> <code>broken-link</code>: link http://example.com/gone is broken (HTTP 404) (line 4, column 7)</li>`)
+	assert.Contains(t, report, `<span class="FAIL">FAIL</span> has title: Title is missing`)
+	assert.Contains(t, report, `<p class="error">Error: failed to fetch the webpage</p>`)
+}
+
+func TestFormatForName(t *testing.T) {
+	for name, want := range map[string]string{"JSON": FormatJSON, "yml": FormatYAML, "md": FormatMarkdown, "html": FormatHTML, "csv": FormatCSV, "xml": FormatJUnit, "sarif": FormatSARIF} {
+		format, ok := FormatForName(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, format, name)
+	}
+	_, ok := FormatForName("pdf")
+	assert.False(t, ok)
+}
@@ -0,0 +1,144 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	ToolName     = "WebPageAnalyzer"
+	ToolVersion  = "1.0"
+	ToolURI      = "https://github.com/naskavinda/webpageanalyzer"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the findings of analyses as a single SARIF 2.1.0 run.
+// Pages that could not be analyzed are reported as failed tool executions.
+func WriteSARIF(w io.Writer, analyses []Analysis) error {
+	driver := sarifDriver{Name: ToolName, Version: ToolVersion, InformationURI: ToolURI}
+	for _, rule := range Rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Level},
+		})
+	}
+
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+	results := []sarifResult{}
+	for _, analysis := range analyses {
+		if analysis.Result == nil {
+			invocation.ExecutionSuccessful = false
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:     LevelError,
+				Message:   sarifMessage{Text: analysis.Error},
+				Locations: []sarifLocation{sarifLocationOf(analysis.URL, 0, 0)},
+			})
+			continue
+		}
+		for _, finding := range Findings(*analysis.Result) {
+			results = append(results, sarifResult{
+				RuleID:    finding.RuleID,
+				RuleIndex: ruleIndex(finding.RuleID),
+				Level:     finding.Level,
+				Message:   sarifMessage{Text: finding.Message},
+				Locations: []sarifLocation{sarifLocationOf(finding.URL, finding.Line, finding.Column)},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs: []sarifRun{{
+			Tool:        sarifTool{Driver: driver},
+			Invocations: []sarifInvocation{invocation},
+			Results:     results,
+		}},
+	})
+}
+
+func sarifLocationOf(uri string, line int, column int) sarifLocation {
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}}}
+	if line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: column}
+	}
+	return location
+}
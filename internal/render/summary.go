@@ -0,0 +1,96 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// summaryRow is one label/value line of the human-readable formats.
+type summaryRow struct {
+	Label string
+	Value string
+}
+
+func summaryRows(result PageAnalysisResponse) []summaryRow {
+	var rows []summaryRow
+	add := func(label string, value string) {
+		rows = append(rows, summaryRow{label, value})
+	}
+	if result.FinalURL != "" && result.FinalURL != result.URL {
+		add("Final URL", result.FinalURL)
+	}
+	add("HTML version", result.HTMLVersion)
+	if result.Doctype != nil {
+		add("Rendering mode", RenderingSummary(*result.Doctype))
+	}
+	title := result.Title
+	if title == "" {
+		title = "(none)"
+	}
+	add("Title", title)
+	add("Headings", HeadingSummary(result.HeadingCounts))
+	add("Links", fmt.Sprintf("%d internal, %d external", result.InternalLinks, result.ExternalLinks))
+	add("Inaccessible links", fmt.Sprintf("%d (internal %d, external %d)",
+		result.InaccessibleLinks, result.InaccessibleInternalLinks, result.InaccessibleExternalLinks))
+	add("Login form", yesNo(result.HasLoginForm))
+	if result.TimedOut {
+		add("Timed out", "yes")
+	}
+	if result.Policy != nil {
+		add("Policy", passFail(result.Policy.Passed))
+	}
+	return rows
+}
+
+// RenderingSummary formats the rendering mode of a doctype, noting a
+// missing or malformed doctype, e.g. "quirks (doctype missing)".
+func RenderingSummary(doctype Doctype) string {
+	switch {
+	case doctype.Missing:
+		return doctype.RenderingMode + " (doctype missing)"
+	case doctype.Malformed:
+		return doctype.RenderingMode + " (doctype malformed)"
+	}
+	return doctype.RenderingMode
+}
+
+// HeadingSummary formats heading counts as "h1: 1, h2: 3", or "none".
+func HeadingSummary(counts map[string]int) string {
+	levels := make([]string, 0, len(counts))
+	for level := range counts {
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return "none"
+	}
+	sort.Strings(levels)
+	parts := make([]string, 0, len(levels))
+	for _, level := range levels {
+		parts = append(parts, fmt.Sprintf("%s: %d", level, counts[level]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func findingText(finding Finding) string {
+	if finding.Line > 0 {
+		return fmt.Sprintf("%s (line %d, column %d)", finding.Message, finding.Line, finding.Column)
+	}
+	return finding.Message
+}
+
+func passFail(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
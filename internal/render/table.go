@@ -0,0 +1,68 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// WriteTable prints one analysis as aligned "label value" rows followed by a
+// blank line.
+func WriteTable(w io.Writer, analysis Analysis) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	row := func(label string, value any) {
+		fmt.Fprintf(tw, "%s\t%v\n", label, value)
+	}
+
+	row("URL", analysis.URL)
+	if analysis.Result == nil {
+		row("Error", analysis.Error)
+		fmt.Fprintln(tw)
+		return tw.Flush()
+	}
+
+	response := analysis.Result
+	if response.FinalURL != "" && response.FinalURL != response.URL {
+		row("Final URL", response.FinalURL)
+	}
+	row("HTML version", response.HTMLVersion)
+	if response.Doctype != nil {
+		row("Rendering mode", RenderingSummary(*response.Doctype))
+	}
+	row("Title", response.Title)
+	row("Headings", HeadingSummary(response.HeadingCounts))
+	row("Internal links", response.InternalLinks)
+	row("External links", response.ExternalLinks)
+	row("Inaccessible links", fmt.Sprintf("%d (internal %d, external %d)",
+		response.InaccessibleLinks, response.InaccessibleInternalLinks, response.InaccessibleExternalLinks))
+	row("Has login form", yesNo(response.HasLoginForm))
+	if response.TimedOut {
+		row("Timed out", "yes")
+	}
+	for _, warning := range response.Warnings {
+		row("Warning", issueMessage(warning))
+	}
+	for _, issue := range response.Errors {
+		row("Error", issueMessage(issue))
+	}
+	for _, link := range response.Links {
+		if link.Status == LinkStatusOK {
+			continue
+		}
+		if link.Line > 0 {
+			row("Link "+link.Status, fmt.Sprintf("%s (line %d)", link.URL, link.Line))
+		} else {
+			row("Link "+link.Status, link.URL)
+		}
+	}
+	if response.Policy != nil {
+		row("Policy", passFail(response.Policy.Passed))
+		for _, rule := range response.Policy.Results {
+			row("  "+passFail(rule.Passed), rule.Name+": "+rule.Explanation)
+		}
+	}
+	fmt.Fprintln(tw)
+	return tw.Flush()
+}
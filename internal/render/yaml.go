@@ -0,0 +1,47 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteYAML writes one YAML document per analysis, shaped like the JSON body
+// of POST /analyzer.
+func WriteYAML(w io.Writer, analyses []Analysis) error {
+	bodies := make([]any, 0, len(analyses))
+	for _, analysis := range analyses {
+		bodies = append(bodies, analysisBody(analysis))
+	}
+	return EncodeYAML(w, bodies...)
+}
+
+// EncodeYAML writes one YAML document per body, using the field names of
+// its JSON encoding.
+func EncodeYAML(w io.Writer, bodies ...any) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	for _, body := range bodies {
+		document, err := toDocument(body)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(document); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// toDocument converts body through JSON so every format uses the field
+// names of the JSON API.
+func toDocument(body any) (any, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var document any
+	err = json.Unmarshal(data, &document)
+	return document, err
+}
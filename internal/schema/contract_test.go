@@ -0,0 +1,247 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// The wire format of the analysis response is a published contract. These
+// tests fail when it changes; after a deliberate, compatible change run
+//
+//	go test ./internal/schema -update
+//
+// and commit the regenerated files.
+var update = flag.Bool("update", false, "rewrite the published schema and golden files")
+
+var (
+	publishedSchema = filepath.Join("..", "..", "api", APIVersion, "analysis-response.schema.json")
+	goldenResponse  = filepath.Join("testdata", "analyzer-response.golden.json")
+)
+
+// fullResponse sets every field of the response so that none is left out
+// of the golden file by omitempty.
+func fullResponse() AnalyzerResponse {
+	redirects := &RedirectChain{
+		Hops:           []RedirectHop{{URL: "http://example.com", StatusCode: 301, Location: "https://example.com/"}},
+		HopCount:       1,
+		FinalURL:       "https://example.com/",
+		Loop:           false,
+		TooManyHops:    false,
+		HTTPSDowngrade: false,
+	}
+	return AnalyzerResponse{
+		URL: "http://example.com",
+		Content: PageAnalysisResponse{
+			URL:         "http://example.com",
+			FinalURL:    "https://example.com/",
+			Redirects:   redirects,
+			HTMLVersion: "HTML5",
+			Doctype: &Doctype{
+				Raw:           `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "xhtml1-strict.dtd">`,
+				Name:          "html",
+				PublicID:      "-//W3C//DTD XHTML 1.0 Strict//EN",
+				SystemID:      "xhtml1-strict.dtd",
+				Family:        DoctypeFamilyXHTML,
+				Version:       "1.0",
+				Variant:       "Strict",
+				DTDURL:        "xhtml1-strict.dtd",
+				RenderingMode: RenderingStandards,
+				Missing:       false,
+				Malformed:     false,
+			},
+			Title:         "Example",
+			HeadingCounts: map[string]int{"h1": 1, "h2": 2},
+			Outline: []HeadingNode{{
+				Level: 1,
+				Text:  "Example",
+				Children: []HeadingNode{
+					{Level: 2, Text: "Usage", Hidden: false},
+					{Level: 2, Text: "Menu", Hidden: true},
+				},
+			}},
+			HeadingIssues:             []Issue{{Code: HeadingIssueHidden, Message: `heading 3 (h2 "Menu") is hidden by aria-hidden`}},
+			InternalLinks:             1,
+			ExternalLinks:             1,
+			InaccessibleLinks:         1,
+			InaccessibleInternalLinks: 0,
+			InaccessibleExternalLinks: 1,
+			LinkStatusCounts:          map[string]int{LinkStatusOK: 1, LinkStatusBroken: 1},
+			SEO: &SEO{
+				Title:             "Example",
+				TitleLength:       7,
+				Description:       "An example page",
+				DescriptionLength: 15,
+				Robots:            "noindex",
+				XRobotsTag:        []string{"nofollow"},
+				Indexable:         false,
+				Followable:        false,
+				Canonical:         "https://example.com/",
+				Alternates:        []HreflangAlternate{{Hreflang: "de", URL: "https://example.de/"}},
+				Prev:              "https://example.com/1",
+				Next:              "https://example.com/3",
+				Refresh:           &MetaRefresh{DelaySeconds: 5, URL: "https://example.com/next"},
+				Viewport:          "width=device-width",
+				Issues:            []Issue{{Code: SEOIssueNotIndexable, Message: "search engines are asked not to index the page"}},
+			},
+			HasLoginForm: true,
+			Forms: []Form{{
+				Index:      0,
+				ID:         "login",
+				Name:       "login",
+				Method:     "POST",
+				Action:     "https://auth.example.com/session",
+				Enctype:    "application/x-www-form-urlencoded",
+				Kind:       FormLogin,
+				Confidence: 1,
+				Fields: []FormField{
+					{Name: "user", ID: "user", Type: "email", Label: "Email", Required: true, Autocomplete: "username"},
+					{Name: "pass", ID: "pass", Type: "password", Label: "Password", Required: true, Autocomplete: "current-password"},
+				},
+				SubmitsOverHTTPS: true,
+				CrossOrigin:      true,
+				HasCSRFToken:     false,
+				Issues:           []Issue{{Code: FormIssueCrossOrigin, Message: "submits to another origin: https://auth.example.com/session"}},
+			}},
+			LinkCheckLimits: LinkCheckLimits{Workers: 16, PerHost: 4, PerHostIntervalMs: 100},
+			Links: []LinkDetail{{
+				URL:            "https://example.org/missing",
+				Href:           "https://example.org/missing",
+				Text:           "Missing",
+				Rel:            "nofollow",
+				Target:         "_blank",
+				Classification: LinkExternal,
+				Status:         LinkStatusBroken,
+				Checked:        true,
+				Method:         "GET",
+				Attempts:       2,
+				StatusCode:     404,
+				LatencyMs:      12,
+				Redirects:      redirects,
+				ErrorKind:      LinkErrorHTTPStatus,
+				Line:           10,
+				Column:         5,
+			}},
+			TimedOut: true,
+			Warnings: []Issue{
+				{Code: IssueBodyTruncated, Message: "body truncated"},
+				{Code: IssueLinkCheckFailed, Check: "links", Message: "1 link(s) could not be checked: 1 timeout"},
+			},
+			Errors:   []Issue{{Code: IssueCheckFailed, Check: "wordCount", Message: "tokenizer failed"}},
+			Checks:   []string{"htmlVersion", "title", "links"},
+			Sections: map[string]any{"custom": map[string]any{"count": 1}},
+			Policy: &PolicyReport{
+				Passed: false,
+				Results: []PolicyRuleResult{{
+					Name:        "no broken links",
+					Field:       "inaccessibleLinks",
+					Op:          "<=",
+					Expected:    0,
+					Actual:      1,
+					Passed:      false,
+					Explanation: "inaccessibleLinks is 1, expected <= 0",
+				}},
+			},
+		},
+	}
+}
+
+func TestPublishedSchemaIsCurrent(t *testing.T) {
+	generated, err := MarshalIndent(Response())
+	assert.NoError(t, err)
+	compareGolden(t, publishedSchema, generated)
+}
+
+func TestWireFormatIsStable(t *testing.T) {
+	encoded, err := json.MarshalIndent(fullResponse(), "", "  ")
+	assert.NoError(t, err)
+	compareGolden(t, goldenResponse, append(encoded, '\n'))
+}
+
+func TestWireFormatMatchesSchema(t *testing.T) {
+	encoded, err := json.Marshal(fullResponse())
+	assert.NoError(t, err)
+	var document any
+	assert.NoError(t, json.Unmarshal(encoded, &document))
+
+	schema := Response()
+	var problems []string
+	checkDeclared(schema["$defs"].(Schema), schema, document, "$", &problems)
+	assert.Empty(t, problems)
+}
+
+func compareGolden(t *testing.T, path string, actual []byte) {
+	t.Helper()
+	if *update {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, actual, 0o644))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s, run with -update to create it: %v", path, err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s is out of date; the wire format changed. Review the change and run with -update.\nexpected:\n%s\nactual:\n%s", path, expected, actual)
+	}
+}
+
+// checkDeclared reports keys of value that schema does not declare and
+// required keys that are missing.
+func checkDeclared(defs Schema, schema Schema, value any, path string, problems *[]string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = defs[strings.TrimPrefix(ref, "#/$defs/")].(Schema)
+	}
+	if anyOf, ok := schema["anyOf"].([]Schema); ok {
+		if value == nil {
+			return
+		}
+		schema = anyOf[0]
+		if ref, ok := schema["$ref"].(string); ok {
+			schema = defs[strings.TrimPrefix(ref, "#/$defs/")].(Schema)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		properties, isStruct := schema["properties"].(Schema)
+		if !isStruct {
+			items, _ := schema["additionalProperties"].(Schema)
+			for key, item := range value {
+				checkDeclared(defs, items, item, path+"."+key, problems)
+			}
+			return
+		}
+		for _, key := range schema["required"].([]string) {
+			if _, ok := value[key]; !ok {
+				*problems = append(*problems, path+"."+key+" is required but missing")
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := properties[key].(Schema)
+			if !ok {
+				*problems = append(*problems, path+"."+key+" is not declared in the schema")
+				continue
+			}
+			checkDeclared(defs, property, value[key], path+"."+key, problems)
+		}
+	case []any:
+		items, _ := schema["items"].(Schema)
+		for _, item := range value {
+			checkDeclared(defs, items, item, path+"[]", problems)
+		}
+	}
+}
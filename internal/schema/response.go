@@ -0,0 +1,26 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+
+	. "github.com/naskavinda/webpageanalyzer/internal/model"
+)
+
+// ResponseID identifies the published schema of the analysis response.
+const ResponseID = "https://github.com/naskavinda/webpageanalyzer/api/" + APIVersion + "/analysis-response.schema.json"
+
+// Response returns the JSON Schema of AnalyzerResponse, the body of a
+// successful analysis in the current API version.
+func Response() Schema {
+	return Generate(reflect.TypeOf(AnalyzerResponse{}), ResponseID, "Web page analysis response "+APIVersion)
+}
+
+// MarshalIndent encodes schema the way it is published.
+func MarshalIndent(schema Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
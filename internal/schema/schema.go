@@ -0,0 +1,129 @@
+// Package schema generates JSON Schemas from Go types so the published
+// contract of the API is derived from the same structs that produce it.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema.
+type Schema map[string]any
+
+// Generate returns the schema of the values of t as encoding/json writes
+// them. Named structs are emitted once under $defs and referenced with
+// $ref; fields without omitempty are required, and nil-able fields without
+// omitempty may be null.
+func Generate(t reflect.Type, id string, title string) Schema {
+	g := generator{defs: Schema{}}
+	root := g.schemaOf(t)
+	schema := Schema{
+		"$schema": Draft,
+		"$id":     id,
+		"title":   title,
+	}
+	for key, value := range root {
+		schema[key] = value
+	}
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+	return schema
+}
+
+type generator struct {
+	defs Schema
+}
+
+func (g *generator) schemaOf(t reflect.Type) Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaOf(t.Elem())
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Interface:
+		return Schema{}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate.
+			g.defs[t.Name()] = Schema{}
+			g.defs[t.Name()] = g.structSchema(t)
+		}
+		return Schema{"$ref": "#/$defs/" + t.Name()}
+	}
+	panic(fmt.Sprintf("schema: unsupported type %s", t))
+}
+
+func (g *generator) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		property := g.schemaOf(field.Type)
+		if !omitEmpty && nullable(field.Type) {
+			property = Schema{"anyOf": []Schema{property, {"type": "null"}}}
+		}
+		properties[name] = property
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+	return Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonName reads the encoding/json name and omitempty option of field.
+func jsonName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	Name     string         `json:"name"`
+	Count    int            `json:"count,omitempty"`
+	Ratio    float64        `json:"ratio"`
+	Tags     []string       `json:"tags"`
+	Labels   map[string]int `json:"labels,omitempty"`
+	Child    *child         `json:"child"`
+	Children []child        `json:"children,omitempty"`
+	Extra    any            `json:"extra,omitempty"`
+	Skipped  string         `json:"-"`
+	Untagged bool
+	hidden   string
+	Nested   struct{ On bool } `json:"nested"`
+}
+
+type child struct {
+	Parent *child `json:"parent,omitempty"`
+}
+
+func TestGenerate(t *testing.T) {
+	schema := Generate(reflect.TypeOf(sample{}), "https://example.com/sample.json", "Sample")
+
+	assert.Equal(t, Draft, schema["$schema"])
+	assert.Equal(t, "https://example.com/sample.json", schema["$id"])
+	assert.Equal(t, "Sample", schema["title"])
+	assert.Equal(t, "#/$defs/sample", schema["$ref"])
+
+	defs := schema["$defs"].(Schema)
+	root := defs["sample"].(Schema)
+	assert.Equal(t, false, root["additionalProperties"])
+	assert.Equal(t, []string{"Untagged", "child", "name", "nested", "ratio", "tags"}, root["required"])
+
+	properties := root["properties"].(Schema)
+	assert.NotContains(t, properties, "Skipped")
+	assert.NotContains(t, properties, "hidden")
+	assert.Equal(t, Schema{"type": "string"}, properties["name"])
+	assert.Equal(t, Schema{"type": "integer"}, properties["count"])
+	assert.Equal(t, Schema{"type": "number"}, properties["ratio"])
+	assert.Equal(t, Schema{"type": "boolean"}, properties["Untagged"])
+	assert.Equal(t, Schema{"anyOf": []Schema{{"type": "array", "items": Schema{"type": "string"}}, {"type": "null"}}}, properties["tags"])
+	assert.Equal(t, Schema{"type": "object", "additionalProperties": Schema{"type": "integer"}}, properties["labels"])
+	assert.Equal(t, Schema{"anyOf": []Schema{{"$ref": "#/$defs/child"}, {"type": "null"}}}, properties["child"])
+	assert.Equal(t, Schema{"type": "array", "items": Schema{"$ref": "#/$defs/child"}}, properties["children"])
+	assert.Equal(t, Schema{}, properties["extra"])
+	assert.Equal(t, "object", properties["nested"].(Schema)["type"])
+
+	// Recursive types are defined once and refer to themselves.
+	childProperties := defs["child"].(Schema)["properties"].(Schema)
+	assert.Equal(t, Schema{"$ref": "#/$defs/child"}, childProperties["parent"])
+}
@@ -6,7 +6,19 @@ import (
 )
 
 func IsValidURL(uri *string) bool {
+	return IsValidURLWithSchemes(uri, "http", "https")
+}
+
+func IsValidURLWithSchemes(uri *string, schemes ...string) bool {
 	*uri = strings.TrimSpace(*uri)
 	parsedURL, err := url.ParseRequestURI(*uri)
-	return err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https")
+	if err != nil {
+		return false
+	}
+	for _, scheme := range schemes {
+		if parsedURL.Scheme == scheme {
+			return true
+		}
+	}
+	return false
 }
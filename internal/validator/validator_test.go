@@ -53,3 +53,17 @@ func TestAnalyze_URLWithTrailingSpaces(t *testing.T) {
 	assert.Equal(t, isValid, true)
 	assert.Equal(t, "https://google.com", pageUrl)
 }
+
+func TestIsValidURLWithSchemes_FileURLValid(c *testing.T) {
+	uri := "file:///tmp/page.html"
+	isValid := IsValidURLWithSchemes(&uri, "file")
+
+	assert.Equal(c, isValid, true)
+}
+
+func TestIsValidURLWithSchemes_SchemeNotAllowed(c *testing.T) {
+	uri := "file:///etc/passwd"
+	isValid := IsValidURLWithSchemes(&uri, "http", "https")
+
+	assert.Equal(c, isValid, false)
+}